@@ -17,38 +17,131 @@ limitations under the License.
 package v1alpha1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // RetentionT TODO
 type RetentionT struct {
 	Period string `json:"period"`
+
+	// MaxCapturesPerObject is the number of captures kept for each original object. 0 keeps all of them
+	// +kubebuilder:validation:Minimum=0
+	MaxCapturesPerObject int `json:"maxCapturesPerObject,omitempty"`
+
+	// MaxCaptures is the number of captures kept by the RecoveryConfig. 0 keeps all of them
+	// +kubebuilder:validation:Minimum=0
+	MaxCaptures int `json:"maxCaptures,omitempty"`
+
+	// MaxPayloadBytes is the total size of the payloads kept by the RecoveryConfig, like 500Mi
+	MaxPayloadBytes *resource.Quantity `json:"maxPayloadBytes,omitempty"`
+
+	// MaxObjectRetention allows the objects to set the retention period of their captures with the
+	// kuberecovery.freepik.com/retention annotation, up to this period. The annotation is ignored when it is not set
+	MaxObjectRetention string `json:"maxObjectRetention,omitempty"`
+
+	// ApplyToExisting recomputes the retentionUntil of the existing captures from their savedAt when the Period changes,
+	// extending or shortening it. Only the captures retained for the Period of the RecoveryConfig are changed
+	ApplyToExisting bool `json:"applyToExisting,omitempty"`
 }
 
 // GvkResource TODO
+// +kubebuilder:validation:XValidation:rule="!has(self.namespaceSelector) || !has(self.namespaces)",message="namespaces and namespaceSelector can not be set together"
 type GvrResourceT struct {
 	APIVersion string   `json:"apiVersion"`
 	Resources  []string `json:"resources"`
 	Namespaces []string `json:"namespaces,omitempty"`
 	Names      []string `json:"names,omitempty"`
+
+	// Retention period of the captures of these resources, overriding the one of the RecoveryConfig.
+	// Only used in resourcesIncluded
+	Retention string `json:"retention,omitempty"`
+
+	// LabelSelector watches just the resources with matching labels. Only used in resourcesIncluded
+	LabelSelector *metav1.LabelSelector `json:"labelSelector,omitempty"`
+
+	// NamespaceSelector watches the resources in the namespaces with matching labels, following the namespaces
+	// as they are created or relabelled. Only used in resourcesIncluded
+	NamespaceSelector *metav1.LabelSelector `json:"namespaceSelector,omitempty"`
+}
+
+// RevisionHistoryT defines the capture of the previous state of the resources when they are updated
+type RevisionHistoryT struct {
+	Enabled bool `json:"enabled"`
+
+	// KeepLast is the number of revisions kept for each resource. 0 keeps all of them until they expire
+	// +kubebuilder:validation:Minimum=0
+	KeepLast int `json:"keepLast,omitempty"`
+}
+
+// SanitizerT defines the fields removed from the captured resources of some kinds, so they are never stored
+type SanitizerT struct {
+	// APIVersion of the resources. When empty, the resources of every version are sanitized
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kinds of the resources. Use "*" to sanitize every kind
+	Kinds []string `json:"kinds"`
+
+	// FieldPaths removed from the resources, as JSON pointers like /spec/replicas.
+	// The * token matches every item of a list or every field of an object
+	FieldPaths []string `json:"fieldPaths"`
 }
 
 // RecoveryConfigSpec defines the desired state of RecoveryConfig.
+// +kubebuilder:validation:XValidation:rule="!has(self.deletionPolicy) || self.deletionPolicy != 'Orphan' || has(self.orphanRetention)",message="orphanRetention is required by the Orphan deletionPolicy"
 type RecoveryConfigSpec struct {
-	ResourcesIncluded []GvrResourceT `json:"resourcesIncluded,omitempty"`
-	ResourcesExcluded []GvrResourceT `json:"resourcesExcluded,omitempty"`
-	Retention         RetentionT     `json:"retention"`
+	ResourcesIncluded []GvrResourceT   `json:"resourcesIncluded,omitempty"`
+	ResourcesExcluded []GvrResourceT   `json:"resourcesExcluded,omitempty"`
+	Retention         RetentionT       `json:"retention"`
+	RevisionHistory   RevisionHistoryT `json:"revisionHistory,omitempty"`
+	Sanitizers        []SanitizerT     `json:"sanitizers,omitempty"`
+
+	// DeletionPolicy applied to the captures when the RecoveryConfig is deleted: Retain keeps them until they expire,
+	// Delete expires all of them, and Orphan keeps them for OrphanRetention since they were captured.
+	// Held captures are never deleted by it
+	// +kubebuilder:validation:Enum=Retain;Delete;Orphan
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// OrphanRetention is the retention period of the captures orphaned by the Orphan deletionPolicy, like 7d
+	OrphanRetention string `json:"orphanRetention,omitempty"`
+}
+
+// RetentionStatusT is the usage of the retention limits of the RecoveryConfig
+type RetentionStatusT struct {
+	// Captures kept by the RecoveryConfig, and the percentage of MaxCaptures they use
+	Captures      int    `json:"captures"`
+	CapturesUsage string `json:"capturesUsage,omitempty"`
+
+	// PayloadBytes is the total size of the payloads kept, and PayloadUsage the percentage of MaxPayloadBytes it uses
+	PayloadBytes int64  `json:"payloadBytes"`
+	PayloadUsage string `json:"payloadUsage,omitempty"`
+}
+
+// RetentionReapplyStatusT is the progress of applying the retention period to the existing captures
+type RetentionReapplyStatusT struct {
+	// Period applied to the captures
+	Period string `json:"period"`
+
+	// Captures retained for the period of the RecoveryConfig, and how many of them have it applied
+	Captures int `json:"captures"`
+	Updated  int `json:"updated"`
+
+	StartTime      metav1.Time  `json:"startTime"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 }
 
 // RecoveryConfigStatus defines the observed state of RecoveryConfig.
 type RecoveryConfigStatus struct {
-	Conditions []metav1.Condition `json:"conditions"`
+	Conditions       []metav1.Condition       `json:"conditions"`
+	Retention        *RetentionStatusT        `json:"retention,omitempty"`
+	RetentionReapply *RetentionReapplyStatusT `json:"retentionReapply,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].status",description=""
+// +kubebuilder:printcolumn:name="Captures",type="integer",JSONPath=".status.retention.captures",description=""
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
 
 // RecoveryConfig is the Schema for the recoveryconfigs API.
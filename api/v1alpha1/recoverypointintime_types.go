@@ -0,0 +1,100 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RestoredItemT is the result of restoring the resource saved in a RecoveryResource
+type RestoredItemT struct {
+	RecoveryResource string `json:"recoveryResource"`
+	APIVersion       string `json:"apiVersion"`
+	Kind             string `json:"kind"`
+	Namespace        string `json:"namespace,omitempty"`
+	Name             string `json:"name"`
+
+	// ConflictStrategy applied when the resource already existed in the cluster
+	ConflictStrategy string `json:"conflictStrategy,omitempty"`
+
+	// Result of the restore: Created, Replaced, Applied, Renamed, Skipped, Unchanged or Failed.
+	// It is InProgress while the resource is restored, and Failed if the restore was interrupted before finishing
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+
+	// Diff between the resource to restore and the live one, reported on dry runs
+	Diff []FieldDiffT `json:"diff,omitempty"`
+
+	// Steps done to bind the restored resource to other resources of the cluster, for the kinds that need it
+	Steps []RestoreStepT `json:"steps,omitempty"`
+}
+
+// RecoveryPointInTimeSpec defines the desired state of RecoveryPointInTime.
+type RecoveryPointInTimeSpec struct {
+	// Namespace to reconstruct
+	Namespace string `json:"namespace"`
+
+	// Time is the point in time the namespace is reconstructed to
+	Time metav1.Time `json:"time"`
+
+	// RecoveryConfigs limits the RecoveryResources used to the ones saved by these RecoveryConfigs.
+	// When empty, the RecoveryResources of every RecoveryConfig are used
+	RecoveryConfigs []string `json:"recoveryConfigs,omitempty"`
+}
+
+// RecoveryPointInTimeStatus defines the observed state of RecoveryPointInTime.
+type RecoveryPointInTimeStatus struct {
+	Conditions     []metav1.Condition `json:"conditions"`
+	StartTime      *metav1.Time       `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time       `json:"completionTime,omitempty"`
+	Items          []RestoredItemT    `json:"items,omitempty"`
+
+	// UIDMapping maps the UIDs of the captured resources to the UIDs of the restored ones.
+	// The ownerReferences of the restored resources are rewired with it
+	UIDMapping map[string]string `json:"uidMapping,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Namespace",type="string",JSONPath=".spec.namespace",description=""
+// +kubebuilder:printcolumn:name="Time",type="string",JSONPath=".spec.time",description=""
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].status",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// RecoveryPointInTime is the Schema for the recoverypointintimes API.
+// It reconstructs a namespace as it was at a point in time from the RecoveryResources saved for it.
+type RecoveryPointInTime struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RecoveryPointInTimeSpec   `json:"spec,omitempty"`
+	Status RecoveryPointInTimeStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RecoveryPointInTimeList contains a list of RecoveryPointInTime.
+type RecoveryPointInTimeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RecoveryPointInTime `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RecoveryPointInTime{}, &RecoveryPointInTimeList{})
+}
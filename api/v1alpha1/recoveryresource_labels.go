@@ -0,0 +1,40 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+// Labels and annotations describing the capture in the RecoveryResources, shared by the controllers setting them
+// and by the conversion of the other versions reading them
+const (
+	// TimeFormat is the format of the times set in the labels, which can not contain colons
+	TimeFormat = "2006-01-02T150405"
+
+	RetentionUntilLabel = "kuberecovery.freepik.com/retentionUntil"
+	SavedAtLabel        = "kuberecovery.freepik.com/savedAt"
+	RecoveryConfigLabel = "kuberecovery.freepik.com/recoveryConfig"
+	OrphanedFromLabel   = "kuberecovery.freepik.com/orphanedFrom"
+	SourceUIDLabel      = "kuberecovery.freepik.com/sourceUid"
+	CaptureReasonLabel  = "kuberecovery.freepik.com/captureReason"
+	RevisionLabel       = "kuberecovery.freepik.com/revision"
+
+	PayloadRefAnnotation      = "kuberecovery.freepik.com/payloadRef"
+	PayloadSizeAnnotation     = "kuberecovery.freepik.com/payloadSize"
+	StaleCacheAnnotation      = "kuberecovery.freepik.com/capturedFromStaleCache"
+	StaleCacheAnnotationValue = "true"
+	SourceCreationAnnotation  = "kuberecovery.freepik.com/sourceCreationTimestamp"
+	RetentionPeriodAnnotation = "kuberecovery.freepik.com/retentionPeriod"
+	RetentionSourceAnnotation = "kuberecovery.freepik.com/retentionSource"
+)
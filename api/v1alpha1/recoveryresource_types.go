@@ -21,15 +21,112 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// FieldDiffT is a field that differs between the resource to restore and the live one
+type FieldDiffT struct {
+	// Path of the field, as a JSON pointer
+	Path string `json:"path"`
+
+	// Live and Saved values of the field, encoded as JSON. They are empty when the field is not set
+	Live  string `json:"live,omitempty"`
+	Saved string `json:"saved,omitempty"`
+}
+
+// RestoreStepT is a step done to restore a resource of a kind bound to other resources of the cluster,
+// like rebinding a PersistentVolumeClaim to its retained volume
+type RestoreStepT struct {
+	Name string `json:"name"`
+
+	// Result of the step: Succeeded, Skipped or Failed
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+}
+
+// RestoreVerificationT is the verification of the health of the restored resource, requested in the
+// annotations of the RecoveryResource
+type RestoreVerificationT struct {
+	// Result of the verification: Pending, Healthy, Unhealthy or RolledBack
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+
+	// Deadline for the restored resource to become healthy, and the time the verification finished
+	Deadline       metav1.Time  `json:"deadline"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// LastRestoreT is the result of the last restore of the resource saved in the RecoveryResource
+type LastRestoreT struct {
+	Time metav1.Time `json:"time"`
+
+	// DryRun is true when the restore was just validated by the API server, without changing the cluster
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// ConflictStrategy applied when the resource already existed in the cluster
+	ConflictStrategy string `json:"conflictStrategy"`
+
+	// Result of the restore: Created, Replaced, Applied, Renamed, Skipped or Failed
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+
+	// APIVersion, Kind, Namespace and Name the resource was restored with, and the UID of the restored resource
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+	UID        string `json:"uid,omitempty"`
+
+	// ConvertedFrom is the captured apiVersion, when it was not served anymore and the resource
+	// was restored with the version served by the cluster
+	ConvertedFrom string `json:"convertedFrom,omitempty"`
+
+	// Diff between the resource to restore and the live resource with the same identity, reported on dry runs
+	Diff []FieldDiffT `json:"diff,omitempty"`
+
+	// Steps done to bind the restored resource to other resources of the cluster, for the kinds that need it
+	Steps []RestoreStepT `json:"steps,omitempty"`
+
+	// Verification of the health of the restored resource, when it is requested
+	Verification *RestoreVerificationT `json:"verification,omitempty"`
+}
+
+// RetentionEventT is a change of the retention of the RecoveryResource requested by hand, kept as audit trail
+type RetentionEventT struct {
+	Time metav1.Time `json:"time"`
+
+	// Action requested: Held, Released or Extended
+	Action string `json:"action"`
+
+	// RequestedBy is the user that requested the action, when the retention audit webhook is enabled,
+	// or the field manager that changed the label otherwise
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	// Extension of the retention, and the retentionUntil it was extended to
+	Extension      string `json:"extension,omitempty"`
+	RetentionUntil string `json:"retentionUntil,omitempty"`
+}
+
 // RecoveryResourceStatus defines the observed state of RecoveryResource.
 type RecoveryResourceStatus struct {
-	Conditions []metav1.Condition `json:"conditions"`
+	Conditions  []metav1.Condition `json:"conditions"`
+	LastRestore *LastRestoreT      `json:"lastRestore,omitempty"`
+	LastDryRun  *LastRestoreT      `json:"lastDryRun,omitempty"`
+
+	// RestoreHistory are the restores done before the last one, the newest last
+	RestoreHistory []LastRestoreT `json:"restoreHistory,omitempty"`
+
+	// Hold is the request holding the RecoveryResource, which is never expired while it is held
+	Hold *RetentionEventT `json:"hold,omitempty"`
+
+	// RetentionHistory is the audit trail of the holds and the extensions of the retention, the newest last
+	RetentionHistory []RetentionEventT `json:"retentionHistory,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
+// +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].status",description=""
+// +kubebuilder:printcolumn:name="Retention Until",type="string",JSONPath=".metadata.labels.kuberecovery\\.freepik\\.com/retentionUntil",description=""
+// +kubebuilder:printcolumn:name="Held",type="string",JSONPath=".metadata.labels.kuberecovery\\.freepik\\.com/hold",description=""
 // +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
 
 // RecoveryResource is the Schema for the recoveryresources API.
@@ -0,0 +1,136 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RecoveryRestoreSelectorT selects the RecoveryResources to restore. Every field set must match
+// +kubebuilder:validation:MinProperties=1
+type RecoveryRestoreSelectorT struct {
+	// Namespaces the resources were deleted from
+	Namespaces []string `json:"namespaces,omitempty"`
+
+	// Kinds of the deleted resources
+	Kinds []string `json:"kinds,omitempty"`
+
+	// Names of the deleted resources. Regexp are supported, so * selects every name
+	Names []string `json:"names,omitempty"`
+
+	// RecoveryConfigs that saved the RecoveryResources
+	RecoveryConfigs []string `json:"recoveryConfigs,omitempty"`
+
+	// DeletedAfter and DeletedBefore define the time window the resources were deleted in
+	DeletedAfter  *metav1.Time `json:"deletedAfter,omitempty"`
+	DeletedBefore *metav1.Time `json:"deletedBefore,omitempty"`
+}
+
+// RestoreOverridesT are the changes done to the saved resource before restoring it
+type RestoreOverridesT struct {
+	// Namespace to restore the resource into, instead of the original one
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name to restore the resource with, instead of the original one
+	Name string `json:"name,omitempty"`
+
+	// Labels and Annotations added to the resource. An empty value removes the key
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// JSONPatch is a JSON patch (RFC 6902) applied to the resource, written in JSON or YAML
+	JSONPatch string `json:"jsonPatch,omitempty"`
+
+	// StrategicMergePatch is a strategic merge patch applied to the resource, written in JSON or YAML.
+	// Kinds not known by the operator, like custom resources, are patched with a JSON merge patch instead
+	StrategicMergePatch string `json:"strategicMergePatch,omitempty"`
+}
+
+// RecoveryRestoreSpec defines the desired state of RecoveryRestore.
+type RecoveryRestoreSpec struct {
+	Selector RecoveryRestoreSelectorT `json:"selector"`
+
+	// Overrides applied to every restored resource. They take precedence over the overrides
+	// set in the annotations of the RecoveryResources
+	Overrides RestoreOverridesT `json:"overrides,omitempty"`
+
+	// ConflictStrategy applied when a restored resource already exists in the cluster:
+	// Fail, Skip, Replace, Apply (server-side apply) or Rename (created again with a generated suffix).
+	// It takes precedence over the strategy set in the annotations of the RecoveryResources
+	// +kubebuilder:validation:Enum=Fail;Skip;Replace;Apply;Rename
+	ConflictStrategy string `json:"conflictStrategy,omitempty"`
+
+	// DryRun validates the restore with the API server without changing the cluster, and reports
+	// the diff between every resource to restore and the live one
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// Cascade restores whole namespaces: the Namespace objects of the selected namespaces are restored too,
+	// when they were captured, before the resources deleted from them
+	Cascade bool `json:"cascade,omitempty"`
+
+	// KindOrder is the order the kinds are restored in. The kinds not listed are restored after them,
+	// in the default dependency order: Namespace, ServiceAccount, ConfigMap, Secret, PersistentVolumeClaim,
+	// workloads, Service and Ingress
+	KindOrder []string `json:"kindOrder,omitempty"`
+}
+
+// RecoveryRestoreStatus defines the observed state of RecoveryRestore.
+type RecoveryRestoreStatus struct {
+	Conditions     []metav1.Condition `json:"conditions"`
+	StartTime      *metav1.Time       `json:"startTime,omitempty"`
+	CompletionTime *metav1.Time       `json:"completionTime,omitempty"`
+	Items          []RestoredItemT    `json:"items,omitempty"`
+
+	// RequestedBy is the user that created the RecoveryRestore, recorded by the admission webhook. When the webhooks
+	// are not served, it is the field manager that set its spec instead, prefixed with fieldManager:
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	// UIDMapping maps the UIDs of the captured resources to the UIDs of the restored ones.
+	// The ownerReferences of the restored resources are rewired with it
+	UIDMapping map[string]string `json:"uidMapping,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:printcolumn:name="Requested By",type="string",JSONPath=".status.requestedBy",description=""
+// +kubebuilder:printcolumn:name="Completion",type="date",JSONPath=".status.completionTime",description=""
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].status",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// RecoveryRestore is the Schema for the recoveryrestores API.
+// It restores every RecoveryResource matching its selector and keeps the result as a record of the restore.
+type RecoveryRestore struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RecoveryRestoreSpec   `json:"spec,omitempty"`
+	Status RecoveryRestoreStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RecoveryRestoreList contains a list of RecoveryRestore.
+type RecoveryRestoreList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RecoveryRestore `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RecoveryRestore{}, &RecoveryRestoreList{})
+}
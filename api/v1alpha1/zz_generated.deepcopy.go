@@ -25,6 +25,21 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldDiffT) DeepCopyInto(out *FieldDiffT) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldDiffT.
+func (in *FieldDiffT) DeepCopy() *FieldDiffT {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldDiffT)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GvrResourceT) DeepCopyInto(out *GvrResourceT) {
 	*out = *in
@@ -43,6 +58,16 @@ func (in *GvrResourceT) DeepCopyInto(out *GvrResourceT) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.LabelSelector != nil {
+		in, out := &in.LabelSelector, &out.LabelSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NamespaceSelector != nil {
+		in, out := &in.NamespaceSelector, &out.NamespaceSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GvrResourceT.
@@ -55,6 +80,37 @@ func (in *GvrResourceT) DeepCopy() *GvrResourceT {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LastRestoreT) DeepCopyInto(out *LastRestoreT) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = make([]FieldDiffT, len(*in))
+		copy(*out, *in)
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]RestoreStepT, len(*in))
+		copy(*out, *in)
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(RestoreVerificationT)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LastRestoreT.
+func (in *LastRestoreT) DeepCopy() *LastRestoreT {
+	if in == nil {
+		return nil
+	}
+	out := new(LastRestoreT)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RecoveryConfig) DeepCopyInto(out *RecoveryConfig) {
 	*out = *in
@@ -131,7 +187,15 @@ func (in *RecoveryConfigSpec) DeepCopyInto(out *RecoveryConfigSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	out.Retention = in.Retention
+	in.Retention.DeepCopyInto(&out.Retention)
+	out.RevisionHistory = in.RevisionHistory
+	if in.Sanitizers != nil {
+		in, out := &in.Sanitizers, &out.Sanitizers
+		*out = make([]SanitizerT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryConfigSpec.
@@ -154,6 +218,16 @@ func (in *RecoveryConfigStatus) DeepCopyInto(out *RecoveryConfigStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(RetentionStatusT)
+		**out = **in
+	}
+	if in.RetentionReapply != nil {
+		in, out := &in.RetentionReapply, &out.RetentionReapply
+		*out = new(RetentionReapplyStatusT)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryConfigStatus.
@@ -166,6 +240,130 @@ func (in *RecoveryConfigStatus) DeepCopy() *RecoveryConfigStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryPointInTime) DeepCopyInto(out *RecoveryPointInTime) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryPointInTime.
+func (in *RecoveryPointInTime) DeepCopy() *RecoveryPointInTime {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryPointInTime)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RecoveryPointInTime) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryPointInTimeList) DeepCopyInto(out *RecoveryPointInTimeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RecoveryPointInTime, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryPointInTimeList.
+func (in *RecoveryPointInTimeList) DeepCopy() *RecoveryPointInTimeList {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryPointInTimeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RecoveryPointInTimeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryPointInTimeSpec) DeepCopyInto(out *RecoveryPointInTimeSpec) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.RecoveryConfigs != nil {
+		in, out := &in.RecoveryConfigs, &out.RecoveryConfigs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryPointInTimeSpec.
+func (in *RecoveryPointInTimeSpec) DeepCopy() *RecoveryPointInTimeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryPointInTimeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryPointInTimeStatus) DeepCopyInto(out *RecoveryPointInTimeStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RestoredItemT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UIDMapping != nil {
+		in, out := &in.UIDMapping, &out.UIDMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryPointInTimeStatus.
+func (in *RecoveryPointInTimeStatus) DeepCopy() *RecoveryPointInTimeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryPointInTimeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RecoveryResource) DeepCopyInto(out *RecoveryResource) {
 	*out = *in
@@ -235,6 +433,35 @@ func (in *RecoveryResourceStatus) DeepCopyInto(out *RecoveryResourceStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastRestore != nil {
+		in, out := &in.LastRestore, &out.LastRestore
+		*out = new(LastRestoreT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastDryRun != nil {
+		in, out := &in.LastDryRun, &out.LastDryRun
+		*out = new(LastRestoreT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestoreHistory != nil {
+		in, out := &in.RestoreHistory, &out.RestoreHistory
+		*out = make([]LastRestoreT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hold != nil {
+		in, out := &in.Hold, &out.Hold
+		*out = new(RetentionEventT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetentionHistory != nil {
+		in, out := &in.RetentionHistory, &out.RetentionHistory
+		*out = make([]RetentionEventT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryResourceStatus.
@@ -247,9 +474,322 @@ func (in *RecoveryResourceStatus) DeepCopy() *RecoveryResourceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryRestore) DeepCopyInto(out *RecoveryRestore) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryRestore.
+func (in *RecoveryRestore) DeepCopy() *RecoveryRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RecoveryRestore) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryRestoreList) DeepCopyInto(out *RecoveryRestoreList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RecoveryRestore, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryRestoreList.
+func (in *RecoveryRestoreList) DeepCopy() *RecoveryRestoreList {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryRestoreList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RecoveryRestoreList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryRestoreSelectorT) DeepCopyInto(out *RecoveryRestoreSelectorT) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RecoveryConfigs != nil {
+		in, out := &in.RecoveryConfigs, &out.RecoveryConfigs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeletedAfter != nil {
+		in, out := &in.DeletedAfter, &out.DeletedAfter
+		*out = (*in).DeepCopy()
+	}
+	if in.DeletedBefore != nil {
+		in, out := &in.DeletedBefore, &out.DeletedBefore
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryRestoreSelectorT.
+func (in *RecoveryRestoreSelectorT) DeepCopy() *RecoveryRestoreSelectorT {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryRestoreSelectorT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryRestoreSpec) DeepCopyInto(out *RecoveryRestoreSpec) {
+	*out = *in
+	in.Selector.DeepCopyInto(&out.Selector)
+	in.Overrides.DeepCopyInto(&out.Overrides)
+	if in.KindOrder != nil {
+		in, out := &in.KindOrder, &out.KindOrder
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryRestoreSpec.
+func (in *RecoveryRestoreSpec) DeepCopy() *RecoveryRestoreSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryRestoreSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryRestoreStatus) DeepCopyInto(out *RecoveryRestoreStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RestoredItemT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UIDMapping != nil {
+		in, out := &in.UIDMapping, &out.UIDMapping
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryRestoreStatus.
+func (in *RecoveryRestoreStatus) DeepCopy() *RecoveryRestoreStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryRestoreStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreOverridesT) DeepCopyInto(out *RestoreOverridesT) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreOverridesT.
+func (in *RestoreOverridesT) DeepCopy() *RestoreOverridesT {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreOverridesT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreStepT) DeepCopyInto(out *RestoreStepT) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreStepT.
+func (in *RestoreStepT) DeepCopy() *RestoreStepT {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreStepT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerificationT) DeepCopyInto(out *RestoreVerificationT) {
+	*out = *in
+	in.Deadline.DeepCopyInto(&out.Deadline)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerificationT.
+func (in *RestoreVerificationT) DeepCopy() *RestoreVerificationT {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreVerificationT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoredItemT) DeepCopyInto(out *RestoredItemT) {
+	*out = *in
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = make([]FieldDiffT, len(*in))
+		copy(*out, *in)
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]RestoreStepT, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoredItemT.
+func (in *RestoredItemT) DeepCopy() *RestoredItemT {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoredItemT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionEventT) DeepCopyInto(out *RetentionEventT) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionEventT.
+func (in *RetentionEventT) DeepCopy() *RetentionEventT {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionEventT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionReapplyStatusT) DeepCopyInto(out *RetentionReapplyStatusT) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionReapplyStatusT.
+func (in *RetentionReapplyStatusT) DeepCopy() *RetentionReapplyStatusT {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionReapplyStatusT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionStatusT) DeepCopyInto(out *RetentionStatusT) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionStatusT.
+func (in *RetentionStatusT) DeepCopy() *RetentionStatusT {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionStatusT)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RetentionT) DeepCopyInto(out *RetentionT) {
 	*out = *in
+	if in.MaxPayloadBytes != nil {
+		in, out := &in.MaxPayloadBytes, &out.MaxPayloadBytes
+		x := (*in).DeepCopy()
+		*out = &x
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionT.
@@ -261,3 +801,43 @@ func (in *RetentionT) DeepCopy() *RetentionT {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RevisionHistoryT) DeepCopyInto(out *RevisionHistoryT) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RevisionHistoryT.
+func (in *RevisionHistoryT) DeepCopy() *RevisionHistoryT {
+	if in == nil {
+		return nil
+	}
+	out := new(RevisionHistoryT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SanitizerT) DeepCopyInto(out *SanitizerT) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FieldPaths != nil {
+		in, out := &in.FieldPaths, &out.FieldPaths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SanitizerT.
+func (in *SanitizerT) DeepCopy() *SanitizerT {
+	if in == nil {
+		return nil
+	}
+	out := new(SanitizerT)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,211 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+const (
+	convertStatusError = "error converting the status of recoveryResource %s: %v"
+)
+
+// payloadIdentityT is the identity of the captured resource, always kept in the payload object
+type payloadIdentityT struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace,omitempty"`
+	} `json:"metadata"`
+}
+
+// SetupWebhookWithManager registers the conversion webhook of the RecoveryResources in the webhook server of the Manager
+func (r *RecoveryResource) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+// ConvertTo converts the RecoveryResource to the stored v1alpha1 version, describing the capture in its labels and
+// annotations. The payload object is stored as spec, built from the source when it is empty
+func (src *RecoveryResource) ConvertTo(dstRaw conversion.Hub) error {
+	dst := dstRaw.(*kuberecoveryv1alpha1.RecoveryResource)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	labels := dst.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	annotations := dst.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	// Empty fields delete their labels and annotations, so the fields can be cleared from this version
+	setOrDelete(labels, kuberecoveryv1alpha1.RecoveryConfigLabel, src.Spec.Capture.RecoveryConfig)
+	setOrDelete(labels, kuberecoveryv1alpha1.OrphanedFromLabel, src.Spec.Capture.OrphanedFrom)
+	setOrDelete(labels, kuberecoveryv1alpha1.CaptureReasonLabel, src.Spec.Capture.Reason)
+	setOrDelete(labels, kuberecoveryv1alpha1.SourceUIDLabel, src.Spec.Source.UID)
+	setOrDelete(labels, kuberecoveryv1alpha1.RevisionLabel, formatInt(int64(src.Spec.Capture.Revision)))
+	setOrDelete(labels, kuberecoveryv1alpha1.SavedAtLabel,
+		formatTime(kuberecoveryv1alpha1.TimeFormat, src.Spec.Capture.Time))
+	setOrDelete(labels, kuberecoveryv1alpha1.RetentionUntilLabel,
+		formatTime(kuberecoveryv1alpha1.TimeFormat, src.Spec.Retention.Until))
+
+	setOrDelete(annotations, kuberecoveryv1alpha1.PayloadRefAnnotation, src.Spec.Payload.StorageRef)
+	setOrDelete(annotations, kuberecoveryv1alpha1.PayloadSizeAnnotation, formatInt(src.Spec.Payload.SizeBytes))
+	setOrDelete(annotations, kuberecoveryv1alpha1.RetentionPeriodAnnotation, src.Spec.Retention.Period)
+	setOrDelete(annotations, kuberecoveryv1alpha1.RetentionSourceAnnotation, src.Spec.Retention.Source)
+	setOrDelete(annotations, kuberecoveryv1alpha1.SourceCreationAnnotation,
+		formatTime(time.RFC3339, src.Spec.Source.CreationTimestamp))
+	staleCache := ""
+	if src.Spec.Capture.StaleCache {
+		staleCache = kuberecoveryv1alpha1.StaleCacheAnnotationValue
+	}
+	setOrDelete(annotations, kuberecoveryv1alpha1.StaleCacheAnnotation, staleCache)
+
+	dst.SetLabels(labels)
+	dst.SetAnnotations(annotations)
+
+	dst.Spec = *src.Spec.Payload.Object.DeepCopy()
+	if len(dst.Spec.Raw) == 0 && dst.Spec.Object == nil {
+		identity := payloadIdentityT{APIVersion: src.Spec.Source.APIVersion, Kind: src.Spec.Source.Kind}
+		identity.Metadata.Name = src.Spec.Source.Name
+		identity.Metadata.Namespace = src.Spec.Source.Namespace
+
+		raw, err := json.Marshal(identity)
+		if err != nil {
+			return err
+		}
+		dst.Spec.Raw = raw
+	}
+
+	// Both versions share the shape of the status, so it is converted through JSON
+	err := convertJSON(&src.Status, &dst.Status)
+	if err != nil {
+		return fmt.Errorf(convertStatusError, src.Name, err)
+	}
+
+	return nil
+}
+
+// ConvertFrom converts the stored v1alpha1 RecoveryResource to this version, reading the capture from its labels
+// and annotations. Values that can not be parsed are left empty, so every existing capture can be converted
+func (dst *RecoveryResource) ConvertFrom(srcRaw conversion.Hub) error {
+	src := srcRaw.(*kuberecoveryv1alpha1.RecoveryResource)
+	dst.ObjectMeta = *src.ObjectMeta.DeepCopy()
+
+	labels := src.GetLabels()
+	annotations := src.GetAnnotations()
+
+	identity := payloadIdentityT{}
+	if len(src.Spec.Raw) > 0 {
+		_ = json.Unmarshal(src.Spec.Raw, &identity)
+	}
+
+	dst.Spec.Source = SourceT{
+		APIVersion:        identity.APIVersion,
+		Kind:              identity.Kind,
+		Namespace:         identity.Metadata.Namespace,
+		Name:              identity.Metadata.Name,
+		UID:               labels[kuberecoveryv1alpha1.SourceUIDLabel],
+		CreationTimestamp: parseTime(time.RFC3339, annotations[kuberecoveryv1alpha1.SourceCreationAnnotation]),
+	}
+
+	revision, _ := strconv.Atoi(labels[kuberecoveryv1alpha1.RevisionLabel])
+	dst.Spec.Capture = CaptureT{
+		RecoveryConfig: labels[kuberecoveryv1alpha1.RecoveryConfigLabel],
+		OrphanedFrom:   labels[kuberecoveryv1alpha1.OrphanedFromLabel],
+		Reason:         labels[kuberecoveryv1alpha1.CaptureReasonLabel],
+		Revision:       revision,
+		Time:           parseTime(kuberecoveryv1alpha1.TimeFormat, labels[kuberecoveryv1alpha1.SavedAtLabel]),
+		StaleCache:     annotations[kuberecoveryv1alpha1.StaleCacheAnnotation] == kuberecoveryv1alpha1.StaleCacheAnnotationValue,
+	}
+
+	sizeBytes, _ := strconv.ParseInt(annotations[kuberecoveryv1alpha1.PayloadSizeAnnotation], 10, 64)
+	dst.Spec.Payload = PayloadT{
+		Object:     *src.Spec.DeepCopy(),
+		StorageRef: annotations[kuberecoveryv1alpha1.PayloadRefAnnotation],
+		SizeBytes:  sizeBytes,
+	}
+
+	dst.Spec.Retention = RetentionT{
+		Period: annotations[kuberecoveryv1alpha1.RetentionPeriodAnnotation],
+		Source: annotations[kuberecoveryv1alpha1.RetentionSourceAnnotation],
+		Until:  parseTime(kuberecoveryv1alpha1.TimeFormat, labels[kuberecoveryv1alpha1.RetentionUntilLabel]),
+	}
+
+	err := convertJSON(&src.Status, &dst.Status)
+	if err != nil {
+		return fmt.Errorf(convertStatusError, src.Name, err)
+	}
+
+	return nil
+}
+
+// setOrDelete sets the key of the map to the value, or deletes it when the value is empty
+func setOrDelete(values map[string]string, key, value string) {
+	if value == "" {
+		delete(values, key)
+		return
+	}
+	values[key] = value
+}
+
+// formatInt returns the value in base 10, or an empty string when it is zero
+func formatInt(value int64) string {
+	if value == 0 {
+		return ""
+	}
+	return strconv.FormatInt(value, 10)
+}
+
+// formatTime returns the time in UTC with the layout, or an empty string when it is nil
+func formatTime(layout string, value *metav1.Time) string {
+	if value == nil {
+		return ""
+	}
+	return value.UTC().Format(layout)
+}
+
+// parseTime returns the time in the value with the layout, or nil if it can not be parsed
+func parseTime(layout, value string) *metav1.Time {
+	parsed, err := time.Parse(layout, value)
+	if err != nil {
+		return nil
+	}
+	return &metav1.Time{Time: parsed.UTC()}
+}
+
+// convertJSON converts between the versions of a type sharing the same JSON shape
+func convertJSON(in, out interface{}) error {
+	raw, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
@@ -0,0 +1,325 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+// Times are kept in the labels with second precision in UTC, so just those survive the conversion
+var (
+	testCreationTimestamp = metav1.NewTime(time.Date(2025, 1, 28, 9, 0, 0, 0, time.UTC))
+	testCaptureTime       = metav1.NewTime(time.Date(2025, 1, 30, 15, 10, 1, 0, time.UTC))
+	testRetentionUntil    = metav1.NewTime(time.Date(2025, 2, 6, 15, 10, 1, 0, time.UTC))
+)
+
+func TestRecoveryResourceConversionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name             string
+		recoveryResource *RecoveryResource
+	}{
+		{
+			name: "payload kept inline",
+			recoveryResource: &RecoveryResource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "recoveryconfig-sample-service-test-7f9c2",
+					Labels: map[string]string{"team": "platform"},
+				},
+				Spec: RecoveryResourceSpec{
+					Source: SourceT{
+						APIVersion:        "v1",
+						Kind:              "Service",
+						Namespace:         "default",
+						Name:              "test",
+						UID:               "3f2b8a4e-1c7d-4e2a-9b6f-5d8c7a1e0f42",
+						CreationTimestamp: &testCreationTimestamp,
+					},
+					Capture: CaptureT{
+						RecoveryConfig: "recoveryconfig-sample",
+						Reason:         "update",
+						Revision:       3,
+						Time:           &testCaptureTime,
+						StaleCache:     true,
+					},
+					Payload: PayloadT{
+						Object: runtime.RawExtension{
+							Raw: []byte(`{"apiVersion":"v1","kind":"Service","metadata":{"name":"test","namespace":"default"}}`),
+						},
+						SizeBytes: 84,
+					},
+					Retention: RetentionT{
+						Period: "168h",
+						Source: "config",
+						Until:  &testRetentionUntil,
+					},
+				},
+				Status: RecoveryResourceStatus{
+					Conditions: []metav1.Condition{{
+						Type:               "ResourceSynced",
+						Status:             metav1.ConditionTrue,
+						Reason:             "ResourceSynced",
+						LastTransitionTime: testCaptureTime,
+					}},
+					LastRestore: &RestoreRecordT{
+						Time:             testRetentionUntil,
+						ConflictStrategy: "Skip",
+						Result:           "Created",
+						APIVersion:       "v1",
+						Kind:             "Service",
+						Namespace:        "default",
+						Name:             "test",
+						UID:              "9a1d3c5e-7b2f-4d6a-8e0c-1f3b5d7a9c2e",
+					},
+				},
+			},
+		},
+		{
+			name: "payload kept in the storage backend",
+			recoveryResource: &RecoveryResource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "recoveryconfig-sample-configmap-settings-1b4d8",
+				},
+				Spec: RecoveryResourceSpec{
+					Source: SourceT{
+						APIVersion: "v1",
+						Kind:       "ConfigMap",
+						Namespace:  "default",
+						Name:       "settings",
+					},
+					Capture: CaptureT{
+						RecoveryConfig: "recoveryconfig-sample",
+						Reason:         "delete",
+						Time:           &testCaptureTime,
+					},
+					Payload: PayloadT{
+						Object: runtime.RawExtension{
+							Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings","namespace":"default"}}`),
+						},
+						StorageRef: "s3://captures/recoveryconfig-sample-configmap-settings-1b4d8.json",
+						SizeBytes:  1048576,
+					},
+					Retention: RetentionT{
+						Period: "720h",
+						Source: "manual",
+						Until:  &testRetentionUntil,
+					},
+				},
+			},
+		},
+		{
+			name: "capture orphaned by its RecoveryConfig",
+			recoveryResource: &RecoveryResource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "recoveryconfig-sample-secret-token-9e3f1",
+				},
+				Spec: RecoveryResourceSpec{
+					Source: SourceT{
+						APIVersion: "v1",
+						Kind:       "Secret",
+						Namespace:  "default",
+						Name:       "token",
+					},
+					Capture: CaptureT{
+						OrphanedFrom: "recoveryconfig-sample",
+						Reason:       "delete",
+						Time:         &testCaptureTime,
+					},
+					Payload: PayloadT{
+						Object: runtime.RawExtension{
+							Raw: []byte(`{"apiVersion":"v1","kind":"Secret","metadata":{"name":"token","namespace":"default"}}`),
+						},
+					},
+					Retention: RetentionT{
+						Period: "168h",
+						Source: "orphan",
+						Until:  &testRetentionUntil,
+					},
+				},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hub := &kuberecoveryv1alpha1.RecoveryResource{}
+			if err := test.recoveryResource.ConvertTo(hub); err != nil {
+				t.Fatalf("unexpected error converting to v1alpha1: %v", err)
+			}
+
+			converted := &RecoveryResource{}
+			if err := converted.ConvertFrom(hub); err != nil {
+				t.Fatalf("unexpected error converting from v1alpha1: %v", err)
+			}
+
+			if !equality.Semantic.DeepEqual(converted.Spec, test.recoveryResource.Spec) {
+				t.Errorf("expected spec %+v, got %+v", test.recoveryResource.Spec, converted.Spec)
+			}
+			if !equality.Semantic.DeepEqual(converted.Status, test.recoveryResource.Status) {
+				t.Errorf("expected status %+v, got %+v", test.recoveryResource.Status, converted.Status)
+			}
+			for key, value := range test.recoveryResource.GetLabels() {
+				if converted.GetLabels()[key] != value {
+					t.Errorf("expected label %s=%s, got %s", key, value, converted.GetLabels()[key])
+				}
+			}
+		})
+	}
+}
+
+func TestRecoveryResourceConversionFromStoredVersion(t *testing.T) {
+	stored := &kuberecoveryv1alpha1.RecoveryResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "recoveryconfig-sample-service-test-7f9c2",
+			Labels: map[string]string{
+				kuberecoveryv1alpha1.RecoveryConfigLabel: "recoveryconfig-sample",
+				kuberecoveryv1alpha1.CaptureReasonLabel:  "delete",
+				kuberecoveryv1alpha1.SourceUIDLabel:      "3f2b8a4e-1c7d-4e2a-9b6f-5d8c7a1e0f42",
+				kuberecoveryv1alpha1.SavedAtLabel:        "2025-01-30T151001",
+				kuberecoveryv1alpha1.RetentionUntilLabel: "2025-02-06T151001",
+			},
+			Annotations: map[string]string{
+				kuberecoveryv1alpha1.RetentionPeriodAnnotation: "168h",
+				kuberecoveryv1alpha1.RetentionSourceAnnotation: "config",
+			},
+		},
+		Spec: runtime.RawExtension{
+			Raw: []byte(`{"apiVersion":"v1","kind":"Service","metadata":{"name":"test","namespace":"default"}}`),
+		},
+	}
+
+	converted := &RecoveryResource{}
+	if err := converted.ConvertFrom(stored); err != nil {
+		t.Fatalf("unexpected error converting from v1alpha1: %v", err)
+	}
+
+	expectedSource := SourceT{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Namespace:  "default",
+		Name:       "test",
+		UID:        "3f2b8a4e-1c7d-4e2a-9b6f-5d8c7a1e0f42",
+	}
+	if converted.Spec.Source != expectedSource {
+		t.Errorf("expected source %+v, got %+v", expectedSource, converted.Spec.Source)
+	}
+	if converted.Spec.Capture.Time == nil || !converted.Spec.Capture.Time.Equal(&testCaptureTime) {
+		t.Errorf("expected capture time %v, got %v", testCaptureTime, converted.Spec.Capture.Time)
+	}
+
+	hub := &kuberecoveryv1alpha1.RecoveryResource{}
+	if err := converted.ConvertTo(hub); err != nil {
+		t.Fatalf("unexpected error converting to v1alpha1: %v", err)
+	}
+	if !equality.Semantic.DeepEqual(hub, stored) {
+		t.Errorf("expected %+v, got %+v", stored, hub)
+	}
+}
+
+func TestRecoveryResourceConvertToWithoutPayloadObject(t *testing.T) {
+	recoveryResource := &RecoveryResource{
+		ObjectMeta: metav1.ObjectMeta{Name: "recoveryconfig-sample-deployment-web-4c1a7"},
+		Spec: RecoveryResourceSpec{
+			Source: SourceT{
+				APIVersion: "apps/v1",
+				Kind:       "Deployment",
+				Namespace:  "default",
+				Name:       "web",
+			},
+			Payload: PayloadT{
+				StorageRef: "filesystem://recoveryconfig-sample-deployment-web-4c1a7.json",
+			},
+		},
+	}
+
+	hub := &kuberecoveryv1alpha1.RecoveryResource{}
+	if err := recoveryResource.ConvertTo(hub); err != nil {
+		t.Fatalf("unexpected error converting to v1alpha1: %v", err)
+	}
+
+	identity := payloadIdentityT{}
+	if err := json.Unmarshal(hub.Spec.Raw, &identity); err != nil {
+		t.Fatalf("unexpected error decoding the payload identity: %v", err)
+	}
+	if identity.APIVersion != "apps/v1" || identity.Kind != "Deployment" ||
+		identity.Metadata.Namespace != "default" || identity.Metadata.Name != "web" {
+		t.Errorf("unexpected payload identity %+v", identity)
+	}
+	if hub.GetAnnotations()[kuberecoveryv1alpha1.PayloadRefAnnotation] != recoveryResource.Spec.Payload.StorageRef {
+		t.Errorf("expected the payload reference %s, got %s", recoveryResource.Spec.Payload.StorageRef,
+			hub.GetAnnotations()[kuberecoveryv1alpha1.PayloadRefAnnotation])
+	}
+}
+
+func TestRecoveryResourceConvertToClearedFields(t *testing.T) {
+	stored := &kuberecoveryv1alpha1.RecoveryResource{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "recoveryconfig-sample-configmap-settings-1b4d8",
+			Labels: map[string]string{
+				kuberecoveryv1alpha1.RecoveryConfigLabel: "recoveryconfig-sample",
+				kuberecoveryv1alpha1.CaptureReasonLabel:  "delete",
+				kuberecoveryv1alpha1.SavedAtLabel:        "2025-01-30T151001",
+				kuberecoveryv1alpha1.RetentionUntilLabel: "2025-02-06T151001",
+			},
+			Annotations: map[string]string{
+				kuberecoveryv1alpha1.PayloadRefAnnotation:      "s3://captures/recoveryconfig-sample-configmap-settings-1b4d8.json",
+				kuberecoveryv1alpha1.RetentionPeriodAnnotation: "168h",
+				kuberecoveryv1alpha1.RetentionSourceAnnotation: "config",
+			},
+		},
+		Spec: runtime.RawExtension{
+			Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"settings","namespace":"default"}}`),
+		},
+	}
+
+	converted := &RecoveryResource{}
+	if err := converted.ConvertFrom(stored); err != nil {
+		t.Fatalf("unexpected error converting from v1alpha1: %v", err)
+	}
+
+	// A v1beta1 client orphans the capture and clears the fields not known anymore
+	converted.Spec.Capture.OrphanedFrom = converted.Spec.Capture.RecoveryConfig
+	converted.Spec.Capture.RecoveryConfig = ""
+	converted.Spec.Capture.Reason = ""
+	converted.Spec.Payload.StorageRef = ""
+	converted.Spec.Retention.Period = ""
+	converted.Spec.Retention.Source = ""
+
+	hub := &kuberecoveryv1alpha1.RecoveryResource{}
+	if err := converted.ConvertTo(hub); err != nil {
+		t.Fatalf("unexpected error converting to v1alpha1: %v", err)
+	}
+
+	expectedLabels := map[string]string{
+		kuberecoveryv1alpha1.OrphanedFromLabel:   "recoveryconfig-sample",
+		kuberecoveryv1alpha1.SavedAtLabel:        "2025-01-30T151001",
+		kuberecoveryv1alpha1.RetentionUntilLabel: "2025-02-06T151001",
+	}
+	if !equality.Semantic.DeepEqual(hub.GetLabels(), expectedLabels) {
+		t.Errorf("expected labels %v, got %v", expectedLabels, hub.GetLabels())
+	}
+	if len(hub.GetAnnotations()) != 0 {
+		t.Errorf("expected the annotations to be deleted, got %v", hub.GetAnnotations())
+	}
+}
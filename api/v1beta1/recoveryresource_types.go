@@ -0,0 +1,223 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// SourceT is the identity of the captured resource
+type SourceT struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	UID        string `json:"uid,omitempty"`
+
+	// CreationTimestamp of the captured resource
+	CreationTimestamp *metav1.Time `json:"creationTimestamp,omitempty"`
+}
+
+// PayloadT is the captured resource, kept inline or in the storage backend
+type PayloadT struct {
+	// Object is the captured resource, or just its identity when the payload is kept in the storage backend
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Object runtime.RawExtension `json:"object,omitempty"`
+
+	// StorageRef is the key of the payload in the storage backend, when it is not kept inline
+	StorageRef string `json:"storageRef,omitempty"`
+
+	// SizeBytes is the size of the payload, counted against the retention limits of the RecoveryConfig
+	SizeBytes int64 `json:"sizeBytes,omitempty"`
+}
+
+// CaptureT is when and why the resource was captured
+type CaptureT struct {
+	// RecoveryConfig that captured the resource
+	RecoveryConfig string `json:"recoveryConfig"`
+
+	// OrphanedFrom is the RecoveryConfig that captured the resource, once the capture is orphaned by its Orphan
+	// deletionPolicy. RecoveryConfig is empty then, so a RecoveryConfig created later with the same name does not adopt it
+	OrphanedFrom string `json:"orphanedFrom,omitempty"`
+
+	// Reason of the capture: delete or update
+	Reason string `json:"reason,omitempty"`
+
+	// Revision of the resource, for the captures of its updates
+	Revision int `json:"revision,omitempty"`
+
+	// Time of the capture, which is the deletion time for the deleted resources
+	Time *metav1.Time `json:"time,omitempty"`
+
+	// StaleCache is true when the resource was captured from the cache of the informer,
+	// so it may miss its last changes
+	StaleCache bool `json:"staleCache,omitempty"`
+}
+
+// RetentionT is how long the capture is kept
+type RetentionT struct {
+	// Period the capture is kept for, and where it comes from: object, rule, config, orphan, manual or evicted
+	Period string `json:"period,omitempty"`
+	Source string `json:"source,omitempty"`
+
+	// Until is the time the capture expires
+	Until *metav1.Time `json:"until,omitempty"`
+}
+
+// RecoveryResourceSpec defines the captured resource and how long it is kept.
+type RecoveryResourceSpec struct {
+	Source    SourceT    `json:"source"`
+	Capture   CaptureT   `json:"capture"`
+	Payload   PayloadT   `json:"payload"`
+	Retention RetentionT `json:"retention,omitempty"`
+}
+
+// FieldDiffT is a field that differs between the resource to restore and the live one
+type FieldDiffT struct {
+	// Path of the field, as a JSON pointer
+	Path string `json:"path"`
+
+	// Live and Saved values of the field, encoded as JSON. They are empty when the field is not set
+	Live  string `json:"live,omitempty"`
+	Saved string `json:"saved,omitempty"`
+}
+
+// RestoreStepT is a step done to restore a resource of a kind bound to other resources of the cluster,
+// like rebinding a PersistentVolumeClaim to its retained volume
+type RestoreStepT struct {
+	Name string `json:"name"`
+
+	// Result of the step: Succeeded, Skipped or Failed
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+}
+
+// RestoreVerificationT is the verification of the health of the restored resource
+type RestoreVerificationT struct {
+	// Result of the verification: Pending, Healthy, Unhealthy or RolledBack
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+
+	// Deadline for the restored resource to become healthy, and the time the verification finished
+	Deadline       metav1.Time  `json:"deadline"`
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// RestoreRecordT is the result of a restore of the captured resource
+type RestoreRecordT struct {
+	Time metav1.Time `json:"time"`
+
+	// DryRun is true when the restore was just validated by the API server, without changing the cluster
+	DryRun bool `json:"dryRun,omitempty"`
+
+	// ConflictStrategy applied when the resource already existed in the cluster
+	ConflictStrategy string `json:"conflictStrategy"`
+
+	// Result of the restore: Created, Replaced, Applied, Renamed, Skipped or Failed
+	Result  string `json:"result"`
+	Message string `json:"message,omitempty"`
+
+	// APIVersion, Kind, Namespace and Name the resource was restored with, and the UID of the restored resource
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name,omitempty"`
+	UID        string `json:"uid,omitempty"`
+
+	// ConvertedFrom is the captured apiVersion, when it was not served anymore and the resource
+	// was restored with the version served by the cluster
+	ConvertedFrom string `json:"convertedFrom,omitempty"`
+
+	// Diff between the resource to restore and the live resource with the same identity, reported on dry runs
+	Diff []FieldDiffT `json:"diff,omitempty"`
+
+	// Steps done to bind the restored resource to other resources of the cluster, for the kinds that need it
+	Steps []RestoreStepT `json:"steps,omitempty"`
+
+	// Verification of the health of the restored resource, when it is requested
+	Verification *RestoreVerificationT `json:"verification,omitempty"`
+}
+
+// RetentionEventT is a change of the retention of the RecoveryResource requested by hand, kept as audit trail
+type RetentionEventT struct {
+	Time metav1.Time `json:"time"`
+
+	// Action requested: Held, Released or Extended
+	Action string `json:"action"`
+
+	// RequestedBy is the user that requested the action, or the field manager that changed the label
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	// Extension of the retention, and the retentionUntil it was extended to
+	Extension      string `json:"extension,omitempty"`
+	RetentionUntil string `json:"retentionUntil,omitempty"`
+}
+
+// RecoveryResourceStatus defines the observed state of RecoveryResource.
+type RecoveryResourceStatus struct {
+	Conditions []metav1.Condition `json:"conditions"`
+
+	// LastRestore and LastDryRun are the last restore and the last dry run of the captured resource
+	LastRestore *RestoreRecordT `json:"lastRestore,omitempty"`
+	LastDryRun  *RestoreRecordT `json:"lastDryRun,omitempty"`
+
+	// RestoreHistory are the restores done before the last one, the newest last
+	RestoreHistory []RestoreRecordT `json:"restoreHistory,omitempty"`
+
+	// Hold is the request holding the RecoveryResource, which is never expired while it is held
+	Hold *RetentionEventT `json:"hold,omitempty"`
+
+	// RetentionHistory is the audit trail of the holds and the extensions of the retention, the newest last
+	RetentionHistory []RetentionEventT `json:"retentionHistory,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster
+// +kubebuilder:unservedversion
+// +kubebuilder:printcolumn:name="Kind",type="string",JSONPath=".spec.source.kind",description=""
+// +kubebuilder:printcolumn:name="Namespace",type="string",JSONPath=".spec.source.namespace",description=""
+// +kubebuilder:printcolumn:name="Name",type="string",JSONPath=".spec.source.name",description=""
+// +kubebuilder:printcolumn:name="Reason",type="string",JSONPath=".spec.capture.reason",description=""
+// +kubebuilder:printcolumn:name="Retention Until",type="date",JSONPath=".spec.retention.until",description=""
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"ResourceSynced\")].status",description=""
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description=""
+
+// RecoveryResource is the Schema for the recoveryresources API.
+// It is converted from the stored v1alpha1 version, where the capture is described in labels and annotations.
+// It is only served when the CRD sets the conversion webhook, as it can not be converted without it.
+type RecoveryResource struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RecoveryResourceSpec   `json:"spec,omitempty"`
+	Status RecoveryResourceStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// RecoveryResourceList contains a list of RecoveryResource.
+type RecoveryResourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RecoveryResource `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&RecoveryResource{}, &RecoveryResourceList{})
+}
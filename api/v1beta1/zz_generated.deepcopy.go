@@ -0,0 +1,325 @@
+//go:build !ignore_autogenerated
+
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Code generated by controller-gen. DO NOT EDIT.
+
+package v1beta1
+
+import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CaptureT) DeepCopyInto(out *CaptureT) {
+	*out = *in
+	if in.Time != nil {
+		in, out := &in.Time, &out.Time
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CaptureT.
+func (in *CaptureT) DeepCopy() *CaptureT {
+	if in == nil {
+		return nil
+	}
+	out := new(CaptureT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FieldDiffT) DeepCopyInto(out *FieldDiffT) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FieldDiffT.
+func (in *FieldDiffT) DeepCopy() *FieldDiffT {
+	if in == nil {
+		return nil
+	}
+	out := new(FieldDiffT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PayloadT) DeepCopyInto(out *PayloadT) {
+	*out = *in
+	in.Object.DeepCopyInto(&out.Object)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PayloadT.
+func (in *PayloadT) DeepCopy() *PayloadT {
+	if in == nil {
+		return nil
+	}
+	out := new(PayloadT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryResource) DeepCopyInto(out *RecoveryResource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryResource.
+func (in *RecoveryResource) DeepCopy() *RecoveryResource {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RecoveryResource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryResourceList) DeepCopyInto(out *RecoveryResourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]RecoveryResource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryResourceList.
+func (in *RecoveryResourceList) DeepCopy() *RecoveryResourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryResourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *RecoveryResourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryResourceSpec) DeepCopyInto(out *RecoveryResourceSpec) {
+	*out = *in
+	in.Source.DeepCopyInto(&out.Source)
+	in.Capture.DeepCopyInto(&out.Capture)
+	in.Payload.DeepCopyInto(&out.Payload)
+	in.Retention.DeepCopyInto(&out.Retention)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryResourceSpec.
+func (in *RecoveryResourceSpec) DeepCopy() *RecoveryResourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryResourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RecoveryResourceStatus) DeepCopyInto(out *RecoveryResourceStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastRestore != nil {
+		in, out := &in.LastRestore, &out.LastRestore
+		*out = new(RestoreRecordT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastDryRun != nil {
+		in, out := &in.LastDryRun, &out.LastDryRun
+		*out = new(RestoreRecordT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestoreHistory != nil {
+		in, out := &in.RestoreHistory, &out.RestoreHistory
+		*out = make([]RestoreRecordT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Hold != nil {
+		in, out := &in.Hold, &out.Hold
+		*out = new(RetentionEventT)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RetentionHistory != nil {
+		in, out := &in.RetentionHistory, &out.RetentionHistory
+		*out = make([]RetentionEventT, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RecoveryResourceStatus.
+func (in *RecoveryResourceStatus) DeepCopy() *RecoveryResourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RecoveryResourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreRecordT) DeepCopyInto(out *RestoreRecordT) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Diff != nil {
+		in, out := &in.Diff, &out.Diff
+		*out = make([]FieldDiffT, len(*in))
+		copy(*out, *in)
+	}
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]RestoreStepT, len(*in))
+		copy(*out, *in)
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(RestoreVerificationT)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreRecordT.
+func (in *RestoreRecordT) DeepCopy() *RestoreRecordT {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreRecordT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreStepT) DeepCopyInto(out *RestoreStepT) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreStepT.
+func (in *RestoreStepT) DeepCopy() *RestoreStepT {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreStepT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreVerificationT) DeepCopyInto(out *RestoreVerificationT) {
+	*out = *in
+	in.Deadline.DeepCopyInto(&out.Deadline)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreVerificationT.
+func (in *RestoreVerificationT) DeepCopy() *RestoreVerificationT {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreVerificationT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionEventT) DeepCopyInto(out *RetentionEventT) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionEventT.
+func (in *RetentionEventT) DeepCopy() *RetentionEventT {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionEventT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RetentionT) DeepCopyInto(out *RetentionT) {
+	*out = *in
+	if in.Until != nil {
+		in, out := &in.Until, &out.Until
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RetentionT.
+func (in *RetentionT) DeepCopy() *RetentionT {
+	if in == nil {
+		return nil
+	}
+	out := new(RetentionT)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SourceT) DeepCopyInto(out *SourceT) {
+	*out = *in
+	if in.CreationTimestamp != nil {
+		in, out := &in.CreationTimestamp, &out.CreationTimestamp
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SourceT.
+func (in *SourceT) DeepCopy() *SourceT {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceT)
+	in.DeepCopyInto(out)
+	return out
+}
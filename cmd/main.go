@@ -20,6 +20,7 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
@@ -35,9 +36,11 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	kuberecoveryv1beta1 "freepik.com/kuberecovery/api/v1beta1"
 	"freepik.com/kuberecovery/internal/controller"
 	"freepik.com/kuberecovery/internal/globals"
 	"freepik.com/kuberecovery/internal/pools"
+	"freepik.com/kuberecovery/internal/storage"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -55,6 +58,7 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(kuberecoveryv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(kuberecoveryv1beta1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -65,6 +69,11 @@ func main() {
 	var secureMetrics bool
 	var enableHTTP2 bool
 	var tlsOpts []func(*tls.Config)
+	var storageOpts storage.Options
+	var enableWebhooks bool
+	var webhookPort int
+	var validatingWebhookConfiguration string
+	var expirationOpts controller.ExpirationOptions
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
@@ -75,6 +84,30 @@ func main() {
 		"If set, the metrics endpoint is served securely via HTTPS. Use --metrics-secure=false to use HTTP instead.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, the admission webhooks are served. They need a TLS certificate in the webhook server cert directory.")
+	flag.IntVar(&webhookPort, "webhook-port", webhook.DefaultPort, "The port the webhook server binds to.")
+	flag.StringVar(&validatingWebhookConfiguration, "validating-webhook-configuration",
+		"kuberecovery-validating-webhook-configuration",
+		"Name of the ValidatingWebhookConfiguration holding the deletion capture webhook, whose rules are synced.")
+	flag.StringVar(&storageOpts.Type, "storage-backend", storage.BackendTypeEtcd,
+		"Where the payload of the captured resources is stored: etcd (inline in the RecoveryResource), "+
+			"filesystem or s3.")
+	flag.StringVar(&storageOpts.FilesystemPath, "storage-filesystem-path", "/var/lib/kuberecovery",
+		"Directory used by the filesystem storage backend, usually a mounted PersistentVolumeClaim.")
+	flag.StringVar(&storageOpts.S3Endpoint, "storage-s3-endpoint", "",
+		"Endpoint of the S3-compatible service used by the s3 storage backend. Defaults to the AWS endpoint of the region.")
+	flag.StringVar(&storageOpts.S3Bucket, "storage-s3-bucket", "", "Bucket used by the s3 storage backend.")
+	flag.StringVar(&storageOpts.S3Region, "storage-s3-region", "us-east-1", "Region used by the s3 storage backend.")
+	flag.BoolVar(&storageOpts.S3Insecure, "storage-s3-insecure", false,
+		"If set, the s3 storage backend uses HTTP instead of HTTPS.")
+	flag.DurationVar(&expirationOpts.Jitter, "expiration-jitter", time.Minute,
+		"Maximum random delay added to the expiration of every RecoveryResource, so the ones expiring together "+
+			"are not deleted at the same time.")
+	flag.Float64Var(&expirationOpts.DeletionRate, "expiration-deletion-rate", 10,
+		"Maximum number of expired RecoveryResources deleted per second. Use 0 to disable the limit.")
+	flag.IntVar(&expirationOpts.DeletionBurst, "expiration-deletion-burst", 20,
+		"Maximum number of expired RecoveryResources deleted at once before the deletion rate applies.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -99,6 +132,7 @@ func main() {
 	}
 
 	webhookServer := webhook.NewServer(webhook.Options{
+		Port:    webhookPort,
 		TLSOpts: tlsOpts,
 	})
 
@@ -156,21 +190,73 @@ func main() {
 		os.Exit(1)
 	}
 
-	if err = (&controller.RecoveryConfigReconciler{
+	// Create the storage backend for the payload of the captured resources.
+	// Credentials for the s3 backend are read from the standard AWS environment variables
+	storageOpts.S3AccessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	storageOpts.S3SecretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	storageBackend, err := storage.NewBackend(storageOpts)
+	if err != nil {
+		setupLog.Error(err, "unable to set up storage backend")
+		os.Exit(1)
+	}
+
+	recoveryConfigReconciler := &controller.RecoveryConfigReconciler{
 		Client:              mgr.GetClient(),
 		Scheme:              mgr.GetScheme(),
 		ResourceWatcherPool: ResourceWatcherPool,
-	}).SetupWithManager(mgr); err != nil {
+		StorageBackend:      storageBackend,
+
+		ValidatingWebhookConfiguration: validatingWebhookConfiguration,
+	}
+	if err = recoveryConfigReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RecoveryConfig")
 		os.Exit(1)
 	}
-	if err = (&controller.RecoveryResourceReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+	if enableWebhooks {
+		if err = recoveryConfigReconciler.SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "RecoveryConfig")
+			os.Exit(1)
+		}
+	}
+	recoveryResourceReconciler := &controller.RecoveryResourceReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		StorageBackend: storageBackend,
+		Expiration:     expirationOpts,
+		Recorder:       mgr.GetEventRecorderFor("kuberecovery"),
+	}
+	if err = recoveryResourceReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "RecoveryResource")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		recoveryResourceReconciler.SetupWebhookWithManager(mgr)
+
+		if err = (&kuberecoveryv1beta1.RecoveryResource{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "RecoveryResource")
+			os.Exit(1)
+		}
+	}
+	if err = (&controller.RecoveryPointInTimeReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		StorageBackend: storageBackend,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RecoveryPointInTime")
+		os.Exit(1)
+	}
+	recoveryRestoreReconciler := &controller.RecoveryRestoreReconciler{
+		Client:         mgr.GetClient(),
+		Scheme:         mgr.GetScheme(),
+		StorageBackend: storageBackend,
+	}
+	if err = recoveryRestoreReconciler.SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "RecoveryRestore")
+		os.Exit(1)
+	}
+	if enableWebhooks {
+		recoveryRestoreReconciler.SetupWebhookWithManager(mgr)
+	}
 	// +kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -17,30 +17,67 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"freepik.com/kuberecovery/internal/globals"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
 	"k8s.io/client-go/restmapper"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/globals"
+	"freepik.com/kuberecovery/internal/storage"
 )
 
 const (
 
 	// Resource types
 	recoveryConfigType         = "RecoveryConfig"
+	recoveryPointInTimeType    = "RecoveryPointInTime"
+	recoveryRestoreType        = "RecoveryRestore"
 	recoveryResourceType       = "RecoveryResource"
 	recoveryResourceTypePlural = "recoveryresources"
 
-	// Sync interval to check if secrets of SearchRuleAction and SearchRuleQueryConnector are up to date
-	defaultSyncInterval = "1m"
+	// Interval to check the health of the restored resources while they are verified
+	restoreVerificationInterval = "10s"
+
+	// Interval to enforce the retention limits of the RecoveryConfigs and report their usage
+	retentionSyncInterval = "1m"
+
+	// Interval and number of captures changed every time the retention period is applied to the existing captures
+	retentionReapplyInterval  = "5s"
+	retentionReapplyBatchSize = 100
+
+	// Minimum interval between the resets of the cached discovery, when a kind is not found in it
+	restMapperResetInterval = 10 * time.Second
+
+	// Time the deletions admitted by the deletion capture webhook wait for the informers, interval to capture the ones
+	// they missed, and time they are kept while the resource still exists, like while its finalizers run
+	pendingDeletionGracePeriod   = 30 * time.Second
+	pendingDeletionSweepInterval = 10 * time.Second
+	pendingDeletionExpiration    = time.Hour
 
 	// Formats
-	recoveryResourceNameFormat = "%s-%s-%s-%s"
-	timeParseFormat            = "2006-01-02T150405"
-	timeParseFormatName        = "20060102150405"
+	recoveryResourceNameFormat         = "%s-%s-%s-%s"
+	recoveryResourceRevisionNameFormat = "%s-r%d"
+	timeParseFormat                    = kuberecoveryv1alpha1.TimeFormat
+	timeParseFormatName                = "20060102150405"
+	payloadKeyFormat                   = "%s.json"
+	retentionUsageFormat               = "%d%%"
+	managedLabelFieldFormat            = `"f:%s"`
+	fieldManagerRequesterFormat        = "fieldManager:%s"
+	redactedDiffValue                  = `"<redacted>"`
+	allNamespacesDescription           = "every namespace"
+	namespaceSelectorKeyFormat         = "namespaceSelector=%s"
 
 	// Error messages
 	resourceNotFoundError              = "%s '%s' resource not found. Ignoring since object must be deleted."
@@ -65,57 +102,316 @@ const (
 	resourceWatcherError               = "error creating event handler for resource %s/%s: %v"
 	recoveryResourceCreationError      = "error creating recoveryResource %s in the cluster: %w"
 	recoveryConfigNotExistsInPoolError = "error recoveryConfig %s not exists in the pool"
+	serializingPayloadError            = "error serializing payload: %v"
+	storePayloadError                  = "error storing payload of recoveryResource %s in the storage backend: %v"
+	fetchPayloadError                  = "error fetching payload %s from the storage backend: %v"
+	deletePayloadError                 = "error deleting payload %s from the storage backend: %v"
+	storageBackendNotConfiguredError   = "payload %s is stored in an external backend but no storage backend is configured"
+	listRecoveryResourcesError         = "error listing recoveryResources: %v"
+	decodeAdmissionObjectError         = "error decoding the object of the admission request %s: %v"
+	expireRecoveryResourceError        = "error expiring recoveryResource %s: %v"
+	getLiveResourceError               = "error getting resource %s from the cluster: %v"
+	updateResourceError                = "error updating resource %s in the cluster: %v"
+	recordRestoreProgressError         = "error recording the progress of the restore in the status of %s: %v"
+	parseRecoveryResourceError         = "error parsing recoveryResource %s: %v"
+	invalidPatternError                = "error matching pattern %s: %v"
+	parseRestoreOverridesError         = "error parsing restore override %s: %v"
+	namespaceOverrideError             = "namespace override set for cluster-scoped resource %s %s"
+	applyRestorePatchError             = "error patching resource %s before restoring it: %v"
+	resourceAlreadyExistsError         = "resource %s already exists in the cluster and the conflict strategy is %s"
+	invalidConflictStrategyError       = "invalid conflict strategy %s set in recoveryResource %s"
+	applyResourceError                 = "error applying resource %s in the cluster: %v"
+	updateLastRestoreError             = "error updating the last restore in the status of recoveryResource %s: %v"
+	versionNotServedError              = "apiVersion %s of kind %s is not served anymore and no other version is served: %v"
+	incompatibleVersionError           = "captured apiVersion %s is not served anymore and the resource is not compatible with %s: %v"
+	getVolumeError                     = "error getting persistentVolume %s: %v"
+	rebindVolumeError                  = "error setting the claimRef of persistentVolume %s to claim %s/%s: %v"
+	parseVerifyTimeoutError            = "error parsing restore verification timeout %s of recoveryResource %s: %v"
+	rollbackResourceError              = "error deleting restored resource %s after failing its verification: %v"
+	updateRetentionError               = "error updating the retention of recoveryResource %s: %v"
+	deletePurgeLabelError              = "error deleting purge label from resource %s: %v"
+	parseOrphanRetentionError          = "error parsing orphan retention %s of recoveryConfig %s: %v"
+	applyDeletionPolicyError           = "error applying deletion policy %s to recoveryResource %s: %v"
+	parseRetentionPeriodError          = "error parsing retention period %s of recoveryConfig %s: %v"
+	reapplyRetentionError              = "error applying retention period %s to recoveryResource %s: %v"
+	unexpectedAdmissionObjectError     = "unexpected object %T in the admission request"
+	invalidRetentionPeriodError        = "invalid retention period, it must be a positive duration like 7d or 12h: %v"
+	resourceNotServedError             = "resource is not served in apiVersion %s"
+	resourceNotWatchableError          = "resource can not be listed and watched"
+	clusterScopedNamespacesError       = "resource %s is cluster-scoped, so it can not be filtered by namespace"
+	listRecoveryConfigsError           = "error listing recoveryConfigs: %v"
+	parseLabelSelectorError            = "error parsing a label selector of apiVersion %s: %v"
+	listNamespacesError                = "error listing namespaces: %v"
+	getNamespaceError                  = "error getting namespace %s: %v"
+	syncDeletionCaptureRulesError      = "error syncing the rules of the deletion capture webhook: %v"
+	listAPIResourcesError              = "error listing the resources served by the cluster: %v"
+	getPendingDeletionError            = "error checking if resource %s/%s/%s/%s admitted for deletion is gone: %v"
+	reviewAccessError                  = "error reviewing the access to %s %s in namespace %q: %v"
 
 	// Info messages
 	resourceExpiredMessage              = "Resource %s is expired, deleting it"
-	resourceRestoreMessage              = "Resource %s has restore label, restoring it"
+	resourceExpirationDelayedMessage    = "Resource %s is expired, delaying its deletion to keep the deletion rate"
+	captureEvictedMessage               = "RecoveryResource %s evicted to keep the retention limits of RecoveryConfig %s"
+	invalidObjectRetentionMessage       = "Invalid retention %s set in resource %s/%s/%s/%s, ignoring it: %v"
+	invalidMaxObjectRetentionMessage    = "Invalid maxObjectRetention %s set in RecoveryConfig %s, ignoring it: %v"
+	objectRetentionClampedMessage       = "Retention %s set in resource %s/%s/%s/%s is over the maximum, using %s"
+	invalidRetentionExtensionMessage    = "Invalid retention extension %s set in RecoveryResource %s, ignoring it: %v"
+	resourceHeldMessage                 = "Resource %s held by %s, it is not expired until it is released"
+	resourceReleasedMessage             = "Resource %s released by %s"
+	retentionExtendedMessage            = "Retention of resource %s extended by %s until %s, requested by %s"
+	resourceExpiredHeldMessage          = "Resource %s is expired but held, keeping it"
+	resourcePurgedMessage               = "RecoveryResource %s purged by %s before its retention until %s"
+	purgeHeldRejectedMessage            = "Purge of RecoveryResource %s requested by %s rejected, as it is held by %s"
+	deletionPolicyAppliedMessage        = "Deletion policy %s applied to %d RecoveryResources of RecoveryConfig %s"
+	captureHeldKeptMessage              = "RecoveryResource %s is held, keeping it after the deletion of RecoveryConfig %s"
+	retentionReappliedMessage           = "Retention period %s of RecoveryConfig %s applied to %d of %d existing captures"
+	apiVersionNotResolvedMessage        = "apiVersion %s could not be resolved through discovery, its resources are not validated: %v"
+	recoveryConfigOverlapMessage        = "%s %s in %s is also captured by RecoveryConfig %s"
+	resourceRelabelledMessage           = "Resource %s/%s/%s/%s stopped matching the label selector, it is not captured"
+	pendingDeletionCapturedMessage      = "Deletion of %s/%s/%s/%s was not seen by the informers, capturing it from the webhook"
+	pendingDeletionExpiredMessage       = "Deletion of %s/%s/%s/%s admitted by the webhook not done after %s, forgetting it"
+	deletionCaptureRulesSyncedMessage   = "Rules of the deletion capture webhook in %s synced to %d apiVersions"
+	resourceRestoreMessage              = "Resource %s has restore label set to %s, restoring it"
 	stopWatchingResourceMessage         = "Stopping watching %s/%s in namespace %s"
 	startWatchingResourceMessage        = "Watching %s/%s in namespace %s"
 	resourceExcludedFromRecoveryMessage = "Resource %s/%s/%s is excluded from recovery"
 	recoveryResourceSavedMessage        = "Resource %s/%s/%s/%s saved as RecoveryResource %s"
-	resourceRestoredSuccessfullyMessage = "Resource %s has been restored as %s/%s %s %s/%s successfully"
+	resourceRestoredSuccessfullyMessage = "Resource %s has been restored as %s/%s successfully: %s"
 	recoveryConfigChangedMessage        = "RecoveryConfig changed, updating %s key in the pool with the new values for informers"
+	resourceAlreadyCapturedMessage      = "Resource %s/%s/%s/%s was already captured as RecoveryResource"
+	tombstoneReceivedMessage            = "Deletion of %s received as tombstone, capturing it from the informer cache"
+	revisionSavedMessage                = "Revision %d of resource %s/%s/%s/%s saved as RecoveryResource %s"
+	pointInTimeComputedMessage          = "Namespace %s at %s needs %d resources from RecoveryResources"
+	restoreSelectedMessage              = "RecoveryRestore %s selected %d RecoveryResources"
+	resourceRenamedMessage              = "Resource already existed, restored as %s"
+	resourceRestoreDryRunMessage        = "Dry run of the restore of resource %s as %s/%s: %s with %d fields changed"
+	resourceConvertedMessage            = "Resource %s captured as %s is restored as %s, as its apiVersion is not served anymore"
+	volumeFoundMessage                  = "PersistentVolume %s found %s with reclaim policy %s"
+	volumeNotFoundMessage               = "PersistentVolume %s does not exist anymore, the claim is restored without it"
+	volumeBoundMessage                  = "PersistentVolume %s is bound to claim %s/%s, the claim is restored without it"
+	volumeNotReclaimableMessage         = "PersistentVolume %s is %s with reclaim policy %s, the claim is restored without it"
+	claimBoundMessage                   = "Claim restored with volumeName %s"
+	volumeReboundMessage                = "ClaimRef of persistentVolume %s set to claim %s/%s"
+	resourceVerifiedMessage             = "Resource %s restored as %s/%s verified as %s: %s"
+	restoredResourceGoneMessage         = "Restored resource does not exist anymore"
+	restoredResourceReplacedMessage     = "Restored resource was replaced by another one with UID %s"
+	verificationStartedMessage          = "Waiting for the restored resource to become healthy"
+	verificationTimeoutMessage          = "Restored resource not healthy after %s: %s"
+	resourceRolledBackMessage           = "%s. Restored resource deleted"
+	rollbackSkippedMessage              = "%s. Not rolled back, as the resource existed before it was %s"
+	resourceHealthyMessage              = "Restored resource is healthy"
+	generationNotObservedMessage        = "Generation %d not observed yet"
+	replicasNotReadyMessage             = "%d of %d replicas updated and %d ready"
+	conditionNotTrueMessage             = "Condition %s is %s: %s"
+	podFailedMessage                    = "Pod failed: %s"
+	restorePartiallyFailedMessage       = "%d of %d resources could not be restored"
+	restoreAccessDeniedMessage          = "%s can not %s %s in %s, so it can not restore the selected resources"
+	restoreInterruptedMessage           = "Restore interrupted before its result was recorded, so it is not retried. Check the resource in the cluster"
 
 	// Finalizer
 	resourceFinalizer              = "kuberecovery.freepik.com/finalizer"
 	recoveryResourceExtraFinalizer = "kuberecovery.freepik.com/protectFinalizer"
 
 	// Labels
-	recoveryResourceRetainUntilLabel    = "kuberecovery.freepik.com/retentionUntil"
-	recoveryResourceSavedAtLabel        = "kuberecovery.freepik.com/savedAt"
-	recoveryResourceRecoveryConfigLabel = "kuberecovery.freepik.com/recoveryConfig"
+	recoveryResourceRetainUntilLabel    = kuberecoveryv1alpha1.RetentionUntilLabel
+	recoveryResourceSavedAtLabel        = kuberecoveryv1alpha1.SavedAtLabel
+	recoveryResourceRecoveryConfigLabel = kuberecoveryv1alpha1.RecoveryConfigLabel
+	recoveryResourceOrphanedFromLabel   = kuberecoveryv1alpha1.OrphanedFromLabel
 	recoveryResourceRestoreLabel        = "kuberecovery.freepik.com/restore"
 	recoveryResourceRestoreLabelValue   = "true"
+	recoveryResourceRestoreDryRunValue  = "dryRun"
+	recoveryResourceSourceUIDLabel      = kuberecoveryv1alpha1.SourceUIDLabel
+	recoveryResourceCaptureReasonLabel  = kuberecoveryv1alpha1.CaptureReasonLabel
+	recoveryResourceRevisionLabel       = kuberecoveryv1alpha1.RevisionLabel
+	recoveryResourceHoldLabel           = "kuberecovery.freepik.com/hold"
+	recoveryResourceHoldLabelValue      = "true"
+	recoveryResourceExtendLabel         = "kuberecovery.freepik.com/extendRetention"
+	recoveryResourcePurgeLabel          = "kuberecovery.freepik.com/purge"
+	recoveryResourcePurgeLabelValue     = "true"
+
+	// Restore results
+	restoreResultCreated   = "Created"
+	restoreResultReplaced  = "Replaced"
+	restoreResultApplied   = "Applied"
+	restoreResultRenamed   = "Renamed"
+	restoreResultSkipped   = "Skipped"
+	restoreResultUnchanged = "Unchanged"
+	restoreResultFailed    = "Failed"
+
+	// Result of the items of RecoveryRestores and RecoveryPointInTimes while they are being restored
+	restoreResultInProgress = "InProgress"
+	restoreResultSucceeded  = "Succeeded"
+
+	// Restore steps of the PersistentVolumeClaims, rebinding them to their retained volumes
+	restoreStepFindVolume   = "FindVolume"
+	restoreStepBindClaim    = "BindClaim"
+	restoreStepRebindVolume = "RebindVolume"
+
+	// Results of the verification of the health of the restored resources
+	verificationResultPending    = "Pending"
+	verificationResultHealthy    = "Healthy"
+	verificationResultUnhealthy  = "Unhealthy"
+	verificationResultRolledBack = "RolledBack"
+
+	// Conflict strategies, applied when the restored resource already exists
+	conflictStrategyFail    = "Fail"
+	conflictStrategySkip    = "Skip"
+	conflictStrategyReplace = "Replace"
+	conflictStrategyApply   = "Apply"
+	conflictStrategyRename  = "Rename"
+
+	// Field manager of the resources restored with server-side apply, and suffix of the renamed ones
+	restoreFieldManager     = "kuberecovery"
+	restoreRenameNameFormat = "%s-restored-"
+
+	// Sources of the retention period of the captures, from the most specific
+	retentionSourceObject  = "object"
+	retentionSourceRule    = "rule"
+	retentionSourceConfig  = "config"
+	retentionSourceOrphan  = "orphan"
+	retentionSourceManual  = "manual"
+	retentionSourceEvicted = "evicted"
+
+	// Deletion policies, applied to the captures of a RecoveryConfig when it is deleted
+	deletionPolicyRetain = "Retain"
+	deletionPolicyDelete = "Delete"
+	deletionPolicyOrphan = "Orphan"
+
+	// Actions changing the retention of a RecoveryResource by hand, recorded in its status
+	retentionActionHeld     = "Held"
+	retentionActionReleased = "Released"
+	retentionActionExtended = "Extended"
+
+	// Reasons of the events emitted when a RecoveryResource is purged by hand
+	purgeEventReasonPurged   = "Purged"
+	purgeEventReasonRejected = "PurgeRejected"
+
+	// Maximum number of retention actions and previous restores kept in the status of a RecoveryResource
+	maxRetentionHistory = 20
+	maxRestoreHistory   = 10
+
+	// Capture reasons
+	captureReasonDelete = "delete"
+	captureReasonUpdate = "update"
+
+	// Annotations
+	recoveryResourcePayloadRefAnnotation      = kuberecoveryv1alpha1.PayloadRefAnnotation
+	recoveryResourceStaleCacheAnnotation      = kuberecoveryv1alpha1.StaleCacheAnnotation
+	recoveryResourceStaleCacheAnnotationValue = kuberecoveryv1alpha1.StaleCacheAnnotationValue
+	recoveryResourceSourceCreationAnnotation  = kuberecoveryv1alpha1.SourceCreationAnnotation
+	recoveryResourcePayloadSizeAnnotation     = kuberecoveryv1alpha1.PayloadSizeAnnotation
+	recoveryResourceRetentionAnnotation       = "kuberecovery.freepik.com/retention"
+	recoveryResourceRetentionPeriodAnnotation = kuberecoveryv1alpha1.RetentionPeriodAnnotation
+	recoveryResourceRetentionSourceAnnotation = kuberecoveryv1alpha1.RetentionSourceAnnotation
+	recoveryResourceHoldByAnnotation          = "kuberecovery.freepik.com/holdRequestedBy"
+	recoveryResourceExtendByAnnotation        = "kuberecovery.freepik.com/extendRetentionRequestedBy"
+	recoveryResourcePurgeByAnnotation         = "kuberecovery.freepik.com/purgeRequestedBy"
+	recoveryRestoreRequestedByAnnotation      = "kuberecovery.freepik.com/requestedBy"
+
+	// Restore override annotations
+	recoveryResourceRestoreNamespaceAnnotation           = "kuberecovery.freepik.com/restoreNamespace"
+	recoveryResourceRestoreNameAnnotation                = "kuberecovery.freepik.com/restoreName"
+	recoveryResourceRestoreLabelsAnnotation              = "kuberecovery.freepik.com/restoreLabels"
+	recoveryResourceRestoreAnnotationsAnnotation         = "kuberecovery.freepik.com/restoreAnnotations"
+	recoveryResourceRestoreJSONPatchAnnotation           = "kuberecovery.freepik.com/restoreJsonPatch"
+	recoveryResourceRestoreStrategicMergePatchAnnotation = "kuberecovery.freepik.com/restoreStrategicMergePatch"
+	recoveryResourceRestoreConflictStrategyAnnotation    = "kuberecovery.freepik.com/restoreConflictStrategy"
+
+	// Restore verification annotations
+	recoveryResourceRestoreVerifyTimeoutAnnotation = "kuberecovery.freepik.com/restoreVerifyTimeout"
+	recoveryResourceRestoreRollbackAnnotation      = "kuberecovery.freepik.com/restoreRollbackOnFailure"
+	recoveryResourceRestoreRollbackAnnotationValue = "true"
 )
 
-// getResourceFromKind returns the resource name from the group, version and kind
-func getResourceFromKind(group, version, kind string) (string, error) {
+// metadata fields populated by the API server, removed from the resources to restore them and to compare their content
+var serverPopulatedMetadataFields = []string{
+	"resourceVersion",
+	"uid",
+	"creationTimestamp",
+	"generation",
+	"managedFields",
+	"selfLink",
+	"deletionTimestamp",
+	"deletionGracePeriodSeconds",
+}
 
-	// Get the discovery client
-	discoveryClient := globals.Application.KubeRawCoreClient.Discovery()
+// removeServerPopulatedFields removes the status and the metadata populated by the API server from the content
+// of the resource
+func removeServerPopulatedFields(content map[string]interface{}) {
+	delete(content, "status")
+	for _, field := range serverPopulatedMetadataFields {
+		unstructured.RemoveNestedField(content, "metadata", field)
+	}
+}
 
-	// Get the API group resources
-	groupResources, err := restmapper.GetAPIGroupResources(discoveryClient)
-	if err != nil {
-		return "", err
+// restMapper maps the kinds to their resources for every controller, caching the discovery of the cluster
+var restMapper struct {
+	once      sync.Once
+	mu        sync.Mutex
+	mapper    *restmapper.DeferredDiscoveryRESTMapper
+	lastReset time.Time
+}
+
+// getRESTMapping returns the REST mapping of the kind in the first of the versions served. When the kind is not found,
+// the cached discovery is reset and the kind looked up again, as it may have been installed after it was cached.
+// Resets are spaced by restMapperResetInterval, so kinds that are never served do not run the discovery every time
+func getRESTMapping(groupKind schema.GroupKind, versions ...string) (*meta.RESTMapping, error) {
+	restMapper.once.Do(func() {
+		restMapper.mapper = restmapper.NewDeferredDiscoveryRESTMapper(
+			memory.NewMemCacheClient(globals.Application.KubeRawCoreClient.Discovery()))
+		restMapper.lastReset = time.Now()
+	})
+
+	mapping, err := restMapper.mapper.RESTMapping(groupKind, versions...)
+	if !meta.IsNoMatchError(err) {
+		return mapping, err
 	}
 
-	// Get the REST mapper
-	mapper := restmapper.NewDiscoveryRESTMapper(groupResources)
+	restMapper.mu.Lock()
+	reset := time.Since(restMapper.lastReset) >= restMapperResetInterval
+	if reset {
+		restMapper.lastReset = time.Now()
+	}
+	restMapper.mu.Unlock()
+	if !reset {
+		return mapping, err
+	}
 
-	// Get the group version kind
-	gvk := schema.GroupVersionKind{Group: group, Version: version, Kind: kind}
+	restMapper.mapper.Reset()
+	return restMapper.mapper.RESTMapping(groupKind, versions...)
+}
 
-	// Get the REST mapping for the group version kind
-	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+// getResourceFromKind returns the resource name from the group, version and kind
+func getResourceFromKind(group, version, kind string) (string, error) {
+	mapping, err := getRESTMapping(schema.GroupKind{Group: group, Kind: kind}, version)
 	if err != nil {
 		return "", err
 	}
-
-	// Return the resource
 	return mapping.Resource.Resource, nil
 }
 
+// getFieldManager returns the manager owning the field of the object, or the last manager that changed the object
+// when no one owns it, like the removed fields
+func getFieldManager(obj metav1.Object, fieldName string) string {
+	field := []byte(fmt.Sprintf(managedLabelFieldFormat, fieldName))
+	var lastManager *metav1.ManagedFieldsEntry
+	managedFields := obj.GetManagedFields()
+	for i := range managedFields {
+		entry := &managedFields[i]
+		if entry.FieldsV1 != nil && bytes.Contains(entry.FieldsV1.Raw, field) {
+			return entry.Manager
+		}
+		if lastManager == nil || (entry.Time != nil && lastManager.Time != nil && entry.Time.After(lastManager.Time.Time)) {
+			lastManager = entry
+		}
+	}
+
+	if lastManager == nil {
+		return ""
+	}
+	return lastManager.Manager
+}
+
 // parseDurationWithDays converts "Xd" into X days, or calls time.ParseDuration for formats like "12h"
 func parseDurationWithDays(input string) (time.Duration, error) {
 	// If the string ends with 'd', interpret it as days
@@ -132,3 +428,75 @@ func parseDurationWithDays(input string) (time.Duration, error) {
 	// Otherwise, parse directly with time.ParseDuration
 	return time.ParseDuration(input)
 }
+
+// getPayloadIdentity returns the fields that identify the resource and its owners, without its content.
+// It is stored in the RecoveryResource spec when the payload lives in an external storage backend
+func getPayloadIdentity(obj *unstructured.Unstructured) map[string]interface{} {
+	metadata := map[string]interface{}{
+		"name": obj.GetName(),
+	}
+	if obj.GetNamespace() != "" {
+		metadata["namespace"] = obj.GetNamespace()
+	}
+	if labels, found, _ := unstructured.NestedStringMap(obj.Object, "metadata", "labels"); found {
+		metadata["labels"] = labels
+	}
+	if ownerReferences, found, _ := unstructured.NestedSlice(obj.Object, "metadata", "ownerReferences"); found {
+		metadata["ownerReferences"] = ownerReferences
+	}
+
+	return map[string]interface{}{
+		"apiVersion": obj.GetAPIVersion(),
+		"kind":       obj.GetKind(),
+		"metadata":   metadata,
+	}
+}
+
+// loadRecoveryResourcePayload returns the resource saved in the RecoveryResource, fetching it
+// from the storage backend when the spec only keeps a reference to the payload
+func loadRecoveryResourcePayload(ctx context.Context, backend storage.Backend,
+	resource *kuberecoveryv1alpha1.RecoveryResource) (*unstructured.Unstructured, error) {
+
+	payload := resource.Spec.Raw
+
+	payloadRef := resource.GetAnnotations()[recoveryResourcePayloadRefAnnotation]
+	if payloadRef != "" {
+		if backend == nil {
+			return nil, fmt.Errorf(storageBackendNotConfiguredError, payloadRef)
+		}
+
+		var err error
+		payload, err = backend.Get(ctx, payloadRef)
+		if err != nil {
+			return nil, fmt.Errorf(fetchPayloadError, payloadRef, err)
+		}
+	}
+
+	// Unmarshal the payload to the object to get unstructured.Unstructured
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(payload, &obj.Object); err != nil {
+		return nil, fmt.Errorf(deserializingRawExtensionError, err)
+	}
+
+	return obj, nil
+}
+
+// getRecoveryResourceIdentity returns the apiVersion, kind and metadata of the resource saved in the RecoveryResource.
+// They are always in the spec, also when the payload is stored in an external storage backend
+func getRecoveryResourceIdentity(resource *kuberecoveryv1alpha1.RecoveryResource) (*unstructured.Unstructured, error) {
+	obj := &unstructured.Unstructured{}
+	if err := json.Unmarshal(resource.Spec.Raw, &obj.Object); err != nil {
+		return nil, fmt.Errorf(deserializingRawExtensionError, err)
+	}
+
+	return obj, nil
+}
+
+// getSavedAt returns the time when the RecoveryResource was saved
+func getSavedAt(resource metav1.Object) (time.Time, error) {
+	savedAt, err := time.Parse(timeParseFormat, resource.GetLabels()[recoveryResourceSavedAtLabel])
+	if err != nil {
+		return savedAt, fmt.Errorf(timeParseError, err)
+	}
+	return savedAt, nil
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+
+	"freepik.com/kuberecovery/internal/globals"
+)
+
+// fakeAPIResources are the resources served by the fake clients of the tests
+var fakeAPIResources = []*metav1.APIResourceList{
+	{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "namespaces", Kind: "Namespace"},
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+			{Name: "secrets", Kind: "Secret", Namespaced: true},
+			{Name: "services", Kind: "Service", Namespaced: true},
+			{Name: "pods", Kind: "Pod", Namespaced: true},
+			{Name: "persistentvolumes", Kind: "PersistentVolume"},
+			{Name: "persistentvolumeclaims", Kind: "PersistentVolumeClaim", Namespaced: true},
+		},
+	},
+	{
+		GroupVersion: "batch/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "jobs", Kind: "Job", Namespaced: true},
+		},
+	},
+}
+
+// fakeListKinds are the list kinds of the resources served by the fake dynamic client
+var fakeListKinds = map[schema.GroupVersionResource]string{
+	{Version: "v1", Resource: "namespaces"}:             "NamespaceList",
+	{Version: "v1", Resource: "configmaps"}:             "ConfigMapList",
+	{Version: "v1", Resource: "secrets"}:                "SecretList",
+	{Version: "v1", Resource: "services"}:               "ServiceList",
+	{Version: "v1", Resource: "pods"}:                   "PodList",
+	{Version: "v1", Resource: "persistentvolumes"}:      "PersistentVolumeList",
+	{Version: "v1", Resource: "persistentvolumeclaims"}: "PersistentVolumeClaimList",
+	{Group: "batch", Version: "v1", Resource: "jobs"}:   "JobList",
+
+	{Group: "kuberecovery.freepik.com", Version: "v1alpha1", Resource: "recoveryresources"}: "RecoveryResourceList",
+}
+
+// setupFakeClients replaces the Kubernetes clients of the operator with fake ones holding the objects for the test.
+// The dynamic client is returned, so the test can add reactors to it. The RESTMapper is shared by every test,
+// so every fake client serves the same resources
+func setupFakeClients(t *testing.T, objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	t.Helper()
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), fakeListKinds,
+		objects...)
+	coreClient := kubernetesfake.NewSimpleClientset()
+	coreClient.Discovery().(*fakediscovery.FakeDiscovery).Resources = fakeAPIResources
+
+	previousClient, previousCoreClient := globals.Application.KubeRawClient, globals.Application.KubeRawCoreClient
+	globals.Application.KubeRawClient, globals.Application.KubeRawCoreClient = dynamicClient, coreClient
+	t.Cleanup(func() {
+		globals.Application.KubeRawClient, globals.Application.KubeRawCoreClient = previousClient, previousCoreClient
+	})
+
+	return dynamicClient
+}
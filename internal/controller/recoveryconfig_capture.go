@@ -0,0 +1,345 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/globals"
+)
+
+// captureOptions defines how the resource was captured, so it is recorded in the RecoveryResource
+type captureOptions struct {
+	// reason is the event that triggered the capture: the deletion or an update of the resource
+	reason string
+
+	// revision is the number of the revision for the captures done on updates
+	revision int
+
+	// staleCache is true when the resource comes from the last state known by the informer cache,
+	// not from the deletion event, so the payload may be slightly out of date
+	staleCache bool
+
+	// resource is the name of the resource of the object, used to find the rule of the RecoveryConfig including it
+	resource string
+}
+
+// captureDeletedResource saves the deleted resource as RecoveryResource for the RecoveryConfig,
+// unless it is excluded by the RecoveryConfig or it was already captured (by the webhook or the informer)
+func (r *RecoveryConfigReconciler) captureDeletedResource(ctx context.Context, obj *unstructured.Unstructured,
+	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig, opts captureOptions) {
+
+	logger := log.FromContext(ctx)
+	opts.reason = captureReasonDelete
+
+	// Get the resource name and check if it is excluded to save it as RecoveryResource
+	resource, excluded := isCaptureExcluded(ctx, obj, recoveryConfig)
+	if excluded {
+		return
+	}
+	opts.resource = resource
+
+	// Check if the resource was already captured for this RecoveryConfig
+	captured, err := isResourceCaptured(ctx, obj.GetUID(), recoveryConfig.Name)
+	if err != nil {
+		logger.Info(fmt.Sprintf(listRecoveryResourcesError, err))
+		return
+	}
+	if captured {
+		logger.Info(fmt.Sprintf(resourceAlreadyCapturedMessage, obj.GetAPIVersion(), resource,
+			obj.GetNamespace(), obj.GetName()))
+		return
+	}
+
+	// Save the resource as RecoveryResource
+	recoveryResourceName, err := r.saveRecoveryResource(ctx, obj, recoveryConfig, opts)
+	if err != nil {
+		logger.Info(fmt.Sprintf(saveRecoveryResourceError, obj.GetAPIVersion(), resource,
+			obj.GetNamespace(), obj.GetName(), err))
+		return
+	}
+	logger.Info(fmt.Sprintf(recoveryResourceSavedMessage,
+		obj.GetAPIVersion(), obj.GetKind(), obj.GetNamespace(), obj.GetName(), recoveryResourceName))
+}
+
+// captureUpdatedResource saves the previous state of an updated resource as a new revision for the RecoveryConfig,
+// unless it is excluded by the RecoveryConfig. Revisions over the RecoveryConfig limit are expired, oldest first
+func (r *RecoveryConfigReconciler) captureUpdatedResource(ctx context.Context, obj *unstructured.Unstructured,
+	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) {
+
+	logger := log.FromContext(ctx)
+
+	// Get the resource name and check if it is excluded to save it as RecoveryResource
+	resource, excluded := isCaptureExcluded(ctx, obj, recoveryConfig)
+	if excluded {
+		return
+	}
+
+	// Get the revisions already saved for the resource to number the new one
+	revisions, err := listRevisions(ctx, obj.GetUID(), recoveryConfig.Name)
+	if err != nil {
+		logger.Info(fmt.Sprintf(listRecoveryResourcesError, err))
+		return
+	}
+	revision := 1
+	if len(revisions) > 0 {
+		revision = getRevision(&revisions[len(revisions)-1]) + 1
+	}
+
+	// Save the previous state of the resource as RecoveryResource
+	recoveryResourceName, err := r.saveRecoveryResource(ctx, obj, recoveryConfig, captureOptions{
+		reason:   captureReasonUpdate,
+		revision: revision,
+		resource: resource,
+	})
+	if err != nil {
+		logger.Info(fmt.Sprintf(saveRecoveryResourceError, obj.GetAPIVersion(), resource,
+			obj.GetNamespace(), obj.GetName(), err))
+		return
+	}
+	logger.Info(fmt.Sprintf(revisionSavedMessage, revision,
+		obj.GetAPIVersion(), obj.GetKind(), obj.GetNamespace(), obj.GetName(), recoveryResourceName))
+
+	// Expire the oldest revisions over the limit, the RecoveryResource controller will delete them
+	keepLast := recoveryConfig.Spec.RevisionHistory.KeepLast
+	for i := 0; keepLast > 0 && i < len(revisions)+1-keepLast; i++ {
+		err = expireRecoveryResource(ctx, revisions[i].GetName())
+		if err != nil {
+			logger.Info(fmt.Sprintf(expireRecoveryResourceError, revisions[i].GetName(), err))
+		}
+	}
+}
+
+// isCaptureExcluded returns the resource name of the object and whether it must not be saved as RecoveryResource,
+// because it is excluded by the RecoveryConfig or its resource can not be resolved
+func isCaptureExcluded(ctx context.Context, obj *unstructured.Unstructured,
+	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) (resource string, excluded bool) {
+
+	logger := log.FromContext(ctx)
+
+	// Get the resource name from the group, version and kind
+	resource, err := getResourceFromKind(obj.GroupVersionKind().Group,
+		obj.GroupVersionKind().Version, obj.GroupVersionKind().Kind)
+	if err != nil {
+		logger.Info(fmt.Sprintf(getResourceFromKindError, err))
+		return resource, true
+	}
+
+	// Check if the resource is excluded to save it as RecoveryResource
+	excluded, err = isResourceExcluded(obj, resource, recoveryConfig)
+	if err != nil {
+		logger.Info(err.Error())
+		return resource, true
+	}
+	if excluded {
+		logger.Info(fmt.Sprintf(resourceExcludedFromRecoveryMessage, obj.GetAPIVersion(), resource, obj.GetNamespace()))
+	}
+
+	return resource, excluded
+}
+
+// hasResourceChanged checks if the update changed the resource itself, ignoring the status and
+// the metadata maintained by the API server, so status updates and resyncs do not create revisions
+func hasResourceChanged(oldObj, newObj *unstructured.Unstructured) bool {
+	if oldObj.GetResourceVersion() == newObj.GetResourceVersion() {
+		return false
+	}
+
+	oldContent := oldObj.DeepCopy()
+	newContent := newObj.DeepCopy()
+	removeServerPopulatedFields(oldContent.Object)
+	removeServerPopulatedFields(newContent.Object)
+
+	return !reflect.DeepEqual(oldContent.Object, newContent.Object)
+}
+
+// isResourceExcluded checks if the resource matches any of the ResourcesExcluded of the RecoveryConfig.
+// Resources, namespaces and names are regular expressions, and "*" matches everything
+func isResourceExcluded(obj *unstructured.Unstructured, resource string,
+	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) (bool, error) {
+
+	for _, excluded := range recoveryConfig.Spec.ResourcesExcluded {
+		if excluded.APIVersion != obj.GetAPIVersion() {
+			continue
+		}
+
+		for _, excludedResource := range excluded.Resources {
+			for _, excludedNamespace := range excluded.Namespaces {
+				for _, excludedName := range excluded.Names {
+
+					// resource, namespace and name can be regex
+					resourceMatched, err := matchPattern(excludedResource, resource)
+					if err != nil {
+						return false, fmt.Errorf(regexResourceError, resource, err)
+					}
+					namespaceMatched, err := matchPattern(excludedNamespace, obj.GetNamespace())
+					if err != nil {
+						return false, fmt.Errorf(regexNamespaceError, obj.GetNamespace(), err)
+					}
+					nameMatched, err := matchPattern(excludedName, obj.GetName())
+					if err != nil {
+						return false, fmt.Errorf(regexNameError, obj.GetName(), err)
+					}
+
+					if resourceMatched && namespaceMatched && nameMatched {
+						return true, nil
+					}
+				}
+			}
+		}
+	}
+
+	return false, nil
+}
+
+// matchPattern matches the value against the regular expression. The "*" pattern matches everything
+func matchPattern(pattern, value string) (bool, error) {
+	if pattern == "*" {
+		return true, nil
+	}
+	return regexp.MatchString(pattern, value)
+}
+
+// isResourceCaptured checks if a RecoveryResource already exists for the deletion of the resource UID
+// and RecoveryConfig. Captures done before the capture reason was recorded are deletions, so revisions are
+// excluded instead
+func isResourceCaptured(ctx context.Context, uid types.UID, recoveryConfigName string) (bool, error) {
+	if uid == "" {
+		return false, nil
+	}
+
+	selector := fmt.Sprintf("%s=%s,%s=%s,%s!=%s",
+		recoveryResourceSourceUIDLabel, uid,
+		recoveryResourceRecoveryConfigLabel, recoveryConfigName,
+		recoveryResourceCaptureReasonLabel, captureReasonUpdate)
+
+	list, err := getRecoveryResourceClient().List(ctx, metav1.ListOptions{
+		LabelSelector: selector,
+		Limit:         1,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return len(list.Items) > 0, nil
+}
+
+// listRevisions returns the revisions saved for the resource UID and RecoveryConfig, sorted from the oldest
+func listRevisions(ctx context.Context, uid types.UID, recoveryConfigName string) ([]unstructured.Unstructured, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		recoveryResourceSourceUIDLabel:      string(uid),
+		recoveryResourceRecoveryConfigLabel: recoveryConfigName,
+		recoveryResourceCaptureReasonLabel:  captureReasonUpdate,
+	})
+
+	list, err := getRecoveryResourceClient().List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := list.Items
+	sort.Slice(revisions, func(i, j int) bool {
+		return getRevision(&revisions[i]) < getRevision(&revisions[j])
+	})
+
+	return revisions, nil
+}
+
+// getRevision returns the revision number of a RecoveryResource, or 0 when it is not a revision
+func getRevision(obj metav1.Object) int {
+	revision, err := strconv.Atoi(obj.GetLabels()[recoveryResourceRevisionLabel])
+	if err != nil {
+		return 0
+	}
+	return revision
+}
+
+// expireRecoveryResource sets the retention of the RecoveryResource to now, so the RecoveryResource
+// controller deletes it as any other expired RecoveryResource. Its retention source is set to evicted,
+// so the retention period of its RecoveryConfig is not applied to it again while it is being deleted
+func expireRecoveryResource(ctx context.Context, name string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{
+				recoveryResourceRetainUntilLabel: time.Now().UTC().Format(timeParseFormat),
+			},
+			"annotations": map[string]interface{}{
+				recoveryResourceRetentionSourceAnnotation: retentionSourceEvicted,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = getRecoveryResourceClient().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// setRecoveryResourceRetention changes the retention of the RecoveryResource, recording the period and where it
+// comes from as when it was captured
+func setRecoveryResourceRetention(ctx context.Context, name string, retentionUntil time.Time,
+	period, source string) error {
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{
+				recoveryResourceRetainUntilLabel: retentionUntil.UTC().Format(timeParseFormat),
+			},
+			"annotations": map[string]interface{}{
+				recoveryResourceRetentionPeriodAnnotation: period,
+				recoveryResourceRetentionSourceAnnotation: source,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = getRecoveryResourceClient().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// getRecoveryResourceClient returns the raw dynamic client for the RecoveryResources.
+// It is used instead of the cached one, so the RecoveryResources created a moment ago are also found
+func getRecoveryResourceClient() dynamic.ResourceInterface {
+	gvr := schema.GroupVersionResource{
+		Group:    kuberecoveryv1alpha1.GroupVersion.Group,
+		Version:  kuberecoveryv1alpha1.GroupVersion.Version,
+		Resource: recoveryResourceTypePlural,
+	}
+
+	return globals.Application.KubeRawClient.Resource(gvr)
+}
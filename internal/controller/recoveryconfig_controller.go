@@ -19,6 +19,7 @@ package controller
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/watch"
@@ -29,6 +30,7 @@ import (
 
 	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
 	"freepik.com/kuberecovery/internal/pools"
+	"freepik.com/kuberecovery/internal/storage"
 )
 
 // RecoveryConfigReconciler reconciles a RecoveryConfig object
@@ -36,6 +38,16 @@ type RecoveryConfigReconciler struct {
 	client.Client
 	Scheme              *runtime.Scheme
 	ResourceWatcherPool *pools.ResourceWatcherStore
+	StorageBackend      storage.Backend
+
+	// ValidatingWebhookConfiguration is the name of the configuration holding the deletion capture webhook
+	ValidatingWebhookConfiguration string
+
+	// deletionCaptureEnabled is set when the deletion capture webhook is served, so its rules are synced
+	deletionCaptureEnabled bool
+
+	// pendingDeletions are the deletions admitted by the deletion capture webhook that are not captured yet
+	pendingDeletions *pendingDeletionQueue
 }
 
 // +kubebuilder:rbac:groups=kuberecovery.freepik.com,resources=recoveryconfigs,verbs=get;list;watch;create;update;patch;delete
@@ -73,8 +85,25 @@ func (r *RecoveryConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 	if !kubeRecoveryConfig.DeletionTimestamp.IsZero() {
 		if controllerutil.ContainsFinalizer(kubeRecoveryConfig, resourceFinalizer) {
 
-			// 3.1 Delete the resources associated with the QueryConnector
+			// 3.1 Delete the resources associated with the QueryConnector.
+			// Every informer of the RecoveryConfig is stopped even when some rule fails, so the error is only logged
 			err = r.Watch(ctx, watch.Deleted, kubeRecoveryConfig)
+			if err != nil {
+				logger.Info(fmt.Sprintf(syncTargetError, recoveryConfigType, req.NamespacedName, err.Error()))
+			}
+
+			// The deletion capture webhook stops receiving the deletions of the resources only included by it
+			if syncErr := r.syncDeletionCaptureRules(ctx); syncErr != nil {
+				logger.Info(fmt.Sprintf(syncTargetError, recoveryConfigType, req.NamespacedName, syncErr.Error()))
+			}
+
+			// 3.2 Apply the deletion policy to the captures once no more are saved.
+			// The finalizer is kept on failures, so it is applied again
+			err = r.applyDeletionPolicy(ctx, kubeRecoveryConfig)
+			if err != nil {
+				logger.Info(fmt.Sprintf(syncTargetError, recoveryConfigType, req.NamespacedName, err.Error()))
+				return result, err
+			}
 
 			// Remove the finalizers on Patch CR
 			controllerutil.RemoveFinalizer(kubeRecoveryConfig, resourceFinalizer)
@@ -114,6 +143,33 @@ func (r *RecoveryConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		return result, err
 	}
 
+	// 6.1 Narrow the rules of the deletion capture webhook to the resources included by the RecoveryConfigs
+	err = r.syncDeletionCaptureRules(ctx)
+	if err != nil {
+		r.UpdateConditionKubernetesApiCallFailure(kubeRecoveryConfig)
+		logger.Info(fmt.Sprintf(syncTargetError, recoveryConfigType, req.NamespacedName, err.Error()))
+		return result, err
+	}
+
+	// 7. Apply the retention period to the existing captures and enforce the retention limits, reporting their
+	// progress and usage periodically as captures keep arriving
+	err = r.syncRetention(ctx, kubeRecoveryConfig)
+	if err != nil {
+		r.UpdateConditionKubernetesApiCallFailure(kubeRecoveryConfig)
+		logger.Info(fmt.Sprintf(syncTargetError, recoveryConfigType, req.NamespacedName, err.Error()))
+		return result, err
+	}
+	result.RequeueAfter, err = time.ParseDuration(retentionSyncInterval)
+	if err != nil {
+		return result, err
+	}
+	if isRetentionReapplyPending(kubeRecoveryConfig) {
+		result.RequeueAfter, err = time.ParseDuration(retentionReapplyInterval)
+		if err != nil {
+			return result, err
+		}
+	}
+
 	// 8. Success, update the status
 	r.UpdateConditionSuccess(kubeRecoveryConfig)
 
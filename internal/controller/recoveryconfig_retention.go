@@ -0,0 +1,508 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+// retainedCapture is a RecoveryResource kept by a RecoveryConfig, with what it counts against the retention limits
+type retainedCapture struct {
+	name         string
+	sourceUID    string
+	savedAt      time.Time
+	payloadBytes int64
+	held         bool
+}
+
+// listRetainedCaptures returns the RecoveryResources of the RecoveryConfig that are not expired or are held, sorted from
+// the oldest. RecoveryResources with a retention that can not be parsed are kept, as the controller will report them
+func listRetainedCaptures(ctx context.Context, recoveryConfigName string) ([]retainedCapture, error) {
+	list, err := listCaptures(ctx, recoveryConfigName)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	captures := make([]retainedCapture, 0, len(list.Items))
+	for i := range list.Items {
+		item := &list.Items[i]
+		itemLabels := item.GetLabels()
+
+		held := isHeld(item)
+		retainUntil, err := time.Parse(timeParseFormat, itemLabels[recoveryResourceRetainUntilLabel])
+		if !held && err == nil && !now.Before(retainUntil) {
+			continue
+		}
+		savedAt, _ := time.Parse(timeParseFormat, itemLabels[recoveryResourceSavedAtLabel])
+
+		captures = append(captures, retainedCapture{
+			name:         item.GetName(),
+			sourceUID:    itemLabels[recoveryResourceSourceUIDLabel],
+			savedAt:      savedAt,
+			payloadBytes: getPayloadBytes(item),
+			held:         held,
+		})
+	}
+
+	sort.SliceStable(captures, func(i, j int) bool {
+		if !captures[i].savedAt.Equal(captures[j].savedAt) {
+			return captures[i].savedAt.Before(captures[j].savedAt)
+		}
+		return captures[i].name < captures[j].name
+	})
+
+	return captures, nil
+}
+
+// listCaptures returns every RecoveryResource saved by the RecoveryConfig
+func listCaptures(ctx context.Context, recoveryConfigName string) (*unstructured.UnstructuredList, error) {
+	selector := labels.SelectorFromSet(labels.Set{
+		recoveryResourceRecoveryConfigLabel: recoveryConfigName,
+	})
+
+	return getRecoveryResourceClient().List(ctx, metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+}
+
+// getPayloadBytes returns the size of the payload saved in the RecoveryResource. It is recorded on capture, and
+// computed from the spec for the RecoveryResources saved before it was recorded
+func getPayloadBytes(obj *unstructured.Unstructured) int64 {
+	payloadBytes, err := strconv.ParseInt(obj.GetAnnotations()[recoveryResourcePayloadSizeAnnotation], 10, 64)
+	if err == nil {
+		return payloadBytes
+	}
+
+	spec, err := json.Marshal(obj.Object["spec"])
+	if err != nil {
+		return 0
+	}
+	return int64(len(spec))
+}
+
+// selectEvictedCaptures returns the captures to evict to keep the retention limits, oldest first:
+// first the ones over the limit of their original object, and then the ones over the limits of the RecoveryConfig.
+// Held captures count against the limits, but they are never evicted
+func selectEvictedCaptures(captures []retainedCapture, retention kuberecoveryv1alpha1.RetentionT) map[string]bool {
+	evicted := map[string]bool{}
+
+	if retention.MaxCapturesPerObject > 0 {
+		perObject := map[string][]retainedCapture{}
+		for _, capture := range captures {
+			if capture.sourceUID != "" {
+				perObject[capture.sourceUID] = append(perObject[capture.sourceUID], capture)
+			}
+		}
+		for _, objectCaptures := range perObject {
+			excess := len(objectCaptures) - retention.MaxCapturesPerObject
+			for i := 0; i < len(objectCaptures) && excess > 0; i++ {
+				if objectCaptures[i].held {
+					continue
+				}
+				evicted[objectCaptures[i].name] = true
+				excess--
+			}
+		}
+	}
+
+	var maxPayloadBytes int64
+	if retention.MaxPayloadBytes != nil {
+		maxPayloadBytes = retention.MaxPayloadBytes.Value()
+	}
+
+	kept, keptBytes := 0, int64(0)
+	for _, capture := range captures {
+		if !evicted[capture.name] {
+			kept++
+			keptBytes += capture.payloadBytes
+		}
+	}
+
+	for _, capture := range captures {
+		overCaptures := retention.MaxCaptures > 0 && kept > retention.MaxCaptures
+		overBytes := maxPayloadBytes > 0 && keptBytes > maxPayloadBytes
+		if !overCaptures && !overBytes {
+			break
+		}
+		if evicted[capture.name] || capture.held {
+			continue
+		}
+		evicted[capture.name] = true
+		kept--
+		keptBytes -= capture.payloadBytes
+	}
+
+	return evicted
+}
+
+// enforceRetentionLimits expires the oldest captures of the RecoveryConfig over its count and size limits,
+// so the RecoveryResource controller deletes them. It returns the usage of the limits after the eviction
+func enforceRetentionLimits(ctx context.Context,
+	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) (*kuberecoveryv1alpha1.RetentionStatusT, error) {
+
+	logger := log.FromContext(ctx)
+	retention := recoveryConfig.Spec.Retention
+
+	captures, err := listRetainedCaptures(ctx, recoveryConfig.Name)
+	if err != nil {
+		return nil, fmt.Errorf(listRecoveryResourcesError, err)
+	}
+
+	status := &kuberecoveryv1alpha1.RetentionStatusT{}
+	evictedCaptures := selectEvictedCaptures(captures, retention)
+	for _, capture := range captures {
+		if !evictedCaptures[capture.name] {
+			status.Captures++
+			status.PayloadBytes += capture.payloadBytes
+			continue
+		}
+
+		err = expireRecoveryResource(ctx, capture.name)
+		if err != nil {
+			logger.Info(fmt.Sprintf(expireRecoveryResourceError, capture.name, err))
+			status.Captures++
+			status.PayloadBytes += capture.payloadBytes
+			continue
+		}
+		logger.Info(fmt.Sprintf(captureEvictedMessage, capture.name, recoveryConfig.Name))
+	}
+
+	if retention.MaxCaptures > 0 {
+		status.CapturesUsage = fmt.Sprintf(retentionUsageFormat, int64(status.Captures)*100/int64(retention.MaxCaptures))
+	}
+	if retention.MaxPayloadBytes != nil && retention.MaxPayloadBytes.Value() > 0 {
+		status.PayloadUsage = fmt.Sprintf(retentionUsageFormat, status.PayloadBytes*100/retention.MaxPayloadBytes.Value())
+	}
+
+	return status, nil
+}
+
+// syncRetention applies the retention period to the existing captures when it is requested, and enforces the
+// retention limits of the RecoveryConfig. The progress and the usage of the limits are reported in its status
+func (r *RecoveryConfigReconciler) syncRetention(ctx context.Context,
+	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) error {
+
+	if recoveryConfig.Spec.Retention.ApplyToExisting {
+		err := reapplyRetention(ctx, recoveryConfig)
+		if err != nil {
+			return err
+		}
+	}
+
+	status, err := enforceRetentionLimits(ctx, recoveryConfig)
+	if err != nil {
+		return err
+	}
+	recoveryConfig.Status.Retention = status
+
+	return nil
+}
+
+// getRetentionPeriod returns the retention period of the capture of the object and where it comes from, using the most
+// specific value: the annotation of the object, the rule of the RecoveryConfig including it and the RecoveryConfig.
+// An invalid annotation is ignored, so a typo in an object does not prevent its capture
+func (r *RecoveryConfigReconciler) getRetentionPeriod(ctx context.Context, obj *unstructured.Unstructured,
+	resource string, recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) (period, source string) {
+
+	if period = getObjectRetention(ctx, obj, recoveryConfig); period != "" {
+		return period, retentionSourceObject
+	}
+
+	if period = r.getRuleRetention(ctx, obj, resource, recoveryConfig); period != "" {
+		return period, retentionSourceRule
+	}
+
+	return recoveryConfig.Spec.Retention.Period, retentionSourceConfig
+}
+
+// getObjectRetention returns the retention period set in the annotation of the object, if the RecoveryConfig allows it
+// with a maximum period. Longer periods are clamped to the maximum, so an object can not keep its captures forever
+func getObjectRetention(ctx context.Context, obj *unstructured.Unstructured,
+	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) string {
+
+	logger := log.FromContext(ctx)
+
+	period, exists := obj.GetAnnotations()[recoveryResourceRetentionAnnotation]
+	maxPeriod := recoveryConfig.Spec.Retention.MaxObjectRetention
+	if !exists || maxPeriod == "" {
+		return ""
+	}
+
+	maxDuration, err := parseDurationWithDays(maxPeriod)
+	if err != nil {
+		logger.Info(fmt.Sprintf(invalidMaxObjectRetentionMessage, maxPeriod, recoveryConfig.Name, err))
+		return ""
+	}
+	duration, err := parseDurationWithDays(period)
+	if err != nil {
+		logger.Info(fmt.Sprintf(invalidObjectRetentionMessage, period, obj.GetAPIVersion(),
+			obj.GetKind(), obj.GetNamespace(), obj.GetName(), err))
+		return ""
+	}
+
+	if duration > maxDuration {
+		logger.Info(fmt.Sprintf(objectRetentionClampedMessage, period, obj.GetAPIVersion(),
+			obj.GetKind(), obj.GetNamespace(), obj.GetName(), maxPeriod))
+		return maxPeriod
+	}
+	return period
+}
+
+// getRuleRetention returns the retention period of the rule of the RecoveryConfig including the resource, if any.
+// Rules naming or selecting the namespace of the object take precedence over the ones including every namespace,
+// and rules with a label selector only include the objects matching it
+func (r *RecoveryConfigReconciler) getRuleRetention(ctx context.Context, obj *unstructured.Unstructured,
+	resource string, recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) string {
+
+	wildcardRetention := ""
+	for _, included := range recoveryConfig.Spec.ResourcesIncluded {
+		if included.Retention == "" || included.APIVersion != obj.GetAPIVersion() ||
+			!slices.Contains(included.Resources, resource) {
+			continue
+		}
+		if !matchesLabelSelector(included.LabelSelector, obj.GetLabels()) {
+			continue
+		}
+
+		if included.NamespaceSelector != nil {
+			if r.isNamespaceSelected(ctx, asSelector(included.NamespaceSelector), obj.GetNamespace()) {
+				return included.Retention
+			}
+			continue
+		}
+		if obj.GetNamespace() != "" && slices.Contains(included.Namespaces, obj.GetNamespace()) {
+			return included.Retention
+		}
+		if wildcardRetention == "" && (len(included.Namespaces) == 0 || slices.Contains(included.Namespaces, "*")) {
+			wildcardRetention = included.Retention
+		}
+	}
+
+	return wildcardRetention
+}
+
+// matchesLabelSelector returns true if the labels match the selector. A nil or invalid selector matches everything,
+// as the invalid ones are rejected before creating the informers
+func matchesLabelSelector(labelSelector *metav1.LabelSelector, objLabels map[string]string) bool {
+	return asSelector(labelSelector).Matches(labels.Set(objLabels))
+}
+
+// asSelector returns the label selector as a selector. A nil or invalid selector matches everything,
+// as the invalid ones are rejected before creating the informers
+func asSelector(labelSelector *metav1.LabelSelector) labels.Selector {
+	if labelSelector == nil {
+		return labels.Everything()
+	}
+	selector, err := metav1.LabelSelectorAsSelector(labelSelector)
+	if err != nil {
+		return labels.Everything()
+	}
+	return selector
+}
+
+// applyDeletionPolicy applies the deletion policy of the RecoveryConfig being deleted to its captures.
+// Held captures are not expired by the Delete policy, and the ones orphaned keep their hold
+func (r *RecoveryConfigReconciler) applyDeletionPolicy(ctx context.Context,
+	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) error {
+
+	logger := log.FromContext(ctx)
+	policy := recoveryConfig.Spec.DeletionPolicy
+	if policy == "" || policy == deletionPolicyRetain {
+		return nil
+	}
+
+	var orphanRetention time.Duration
+	if policy == deletionPolicyOrphan {
+		var err error
+		orphanRetention, err = parseDurationWithDays(recoveryConfig.Spec.OrphanRetention)
+		if err != nil {
+			return fmt.Errorf(parseOrphanRetentionError, recoveryConfig.Spec.OrphanRetention, recoveryConfig.Name, err)
+		}
+	}
+
+	list, err := listCaptures(ctx, recoveryConfig.Name)
+	if err != nil {
+		return fmt.Errorf(listRecoveryResourcesError, err)
+	}
+
+	for i := range list.Items {
+		item := &list.Items[i]
+
+		switch policy {
+		case deletionPolicyDelete:
+			if isHeld(item) {
+				logger.Info(fmt.Sprintf(captureHeldKeptMessage, item.GetName(), recoveryConfig.Name))
+				continue
+			}
+			err = expireRecoveryResource(ctx, item.GetName())
+
+		case deletionPolicyOrphan:
+			// Captures without a valid capture time are kept for the whole period from now
+			savedAt, parseErr := getSavedAt(item)
+			if parseErr != nil {
+				savedAt = time.Now().UTC()
+			}
+			err = orphanRecoveryResource(ctx, item.GetName(), recoveryConfig.Name, savedAt.Add(orphanRetention),
+				recoveryConfig.Spec.OrphanRetention)
+		}
+		if err != nil {
+			return fmt.Errorf(applyDeletionPolicyError, policy, item.GetName(), err)
+		}
+	}
+
+	logger.Info(fmt.Sprintf(deletionPolicyAppliedMessage, policy, len(list.Items), recoveryConfig.Name))
+	return nil
+}
+
+// orphanRecoveryResource sets the orphan retention of the capture and moves its recoveryConfig label to the
+// orphanedFrom one, so a RecoveryConfig created later with the same name does not adopt it
+func orphanRecoveryResource(ctx context.Context, name, recoveryConfigName string, retentionUntil time.Time,
+	period string) error {
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": map[string]interface{}{
+				recoveryResourceRetainUntilLabel:    retentionUntil.UTC().Format(timeParseFormat),
+				recoveryResourceRecoveryConfigLabel: nil,
+				recoveryResourceOrphanedFromLabel:   recoveryConfigName,
+			},
+			"annotations": map[string]interface{}{
+				recoveryResourceRetentionPeriodAnnotation: period,
+				recoveryResourceRetentionSourceAnnotation: retentionSourceOrphan,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = getRecoveryResourceClient().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+// getCaptureRecoveryConfig returns the name of the RecoveryConfig that saved the capture, even if it was orphaned
+func getCaptureRecoveryConfig(resource metav1.Object) string {
+	if recoveryConfigName, exists := resource.GetLabels()[recoveryResourceRecoveryConfigLabel]; exists {
+		return recoveryConfigName
+	}
+	return resource.GetLabels()[recoveryResourceOrphanedFromLabel]
+}
+
+// followsConfigRetention returns true if the capture is retained for the period of its RecoveryConfig.
+// Captures saved before the source of their period was recorded are not changed, as it is unknown
+func followsConfigRetention(obj *unstructured.Unstructured) bool {
+	return obj.GetAnnotations()[recoveryResourceRetentionSourceAnnotation] == retentionSourceConfig
+}
+
+// isRetentionReapplyPending returns true while the retention period is being applied to the existing captures
+func isRetentionReapplyPending(recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) bool {
+	return recoveryConfig.Spec.Retention.ApplyToExisting && recoveryConfig.Status.RetentionReapply != nil &&
+		recoveryConfig.Status.RetentionReapply.CompletionTime == nil
+}
+
+// reapplyRetention recomputes the retentionUntil of the captures retained for the period of the RecoveryConfig from
+// their savedAt, so a change of the period extends or shortens them. The captures are changed in batches, and the
+// progress is reported in the status of the RecoveryConfig until all of them have the period applied
+func reapplyRetention(ctx context.Context, recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) error {
+	logger := log.FromContext(ctx)
+	period := recoveryConfig.Spec.Retention.Period
+
+	status := recoveryConfig.Status.RetentionReapply
+	if status != nil && status.Period == period && status.CompletionTime != nil {
+		return nil
+	}
+
+	parsedPeriod, err := parseDurationWithDays(period)
+	if err != nil {
+		return fmt.Errorf(parseRetentionPeriodError, period, recoveryConfig.Name, err)
+	}
+
+	// A new change of the period restarts the progress
+	if status == nil || status.Period != period {
+		status = &kuberecoveryv1alpha1.RetentionReapplyStatusT{
+			Period:    period,
+			StartTime: metav1.Now(),
+		}
+		recoveryConfig.Status.RetentionReapply = status
+	}
+
+	list, err := listCaptures(ctx, recoveryConfig.Name)
+	if err != nil {
+		return fmt.Errorf(listRecoveryResourcesError, err)
+	}
+
+	captures, updated, changed := 0, 0, 0
+	for i := range list.Items {
+		item := &list.Items[i]
+		if !followsConfigRetention(item) {
+			continue
+		}
+
+		// Captures without a valid capture time keep their retention, as it can not be computed
+		savedAt, err := getSavedAt(item)
+		if err != nil {
+			logger.Info(fmt.Sprintf(reapplyRetentionError, period, item.GetName(), err))
+			continue
+		}
+		captures++
+
+		retentionUntil := savedAt.Add(parsedPeriod)
+		if item.GetLabels()[recoveryResourceRetainUntilLabel] == retentionUntil.Format(timeParseFormat) &&
+			item.GetAnnotations()[recoveryResourceRetentionPeriodAnnotation] == period {
+			updated++
+			continue
+		}
+		if changed >= retentionReapplyBatchSize {
+			continue
+		}
+
+		err = setRecoveryResourceRetention(ctx, item.GetName(), retentionUntil, period, retentionSourceConfig)
+		if err != nil {
+			logger.Info(fmt.Sprintf(reapplyRetentionError, period, item.GetName(), err))
+			continue
+		}
+		updated++
+		changed++
+	}
+
+	status.Captures = captures
+	status.Updated = updated
+	if updated == captures {
+		now := metav1.Now()
+		status.CompletionTime = &now
+	}
+	if changed > 0 {
+		logger.Info(fmt.Sprintf(retentionReappliedMessage, period, recoveryConfig.Name, updated, captures))
+	}
+
+	return nil
+}
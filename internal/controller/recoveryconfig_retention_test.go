@@ -0,0 +1,191 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+func TestSelectEvictedCaptures(t *testing.T) {
+	savedAt := time.Date(2025, 1, 30, 15, 10, 1, 0, time.UTC)
+	newCapture := func(name, sourceUID string, minutes int, payloadBytes int64, held bool) retainedCapture {
+		return retainedCapture{
+			name:         name,
+			sourceUID:    sourceUID,
+			savedAt:      savedAt.Add(time.Duration(minutes) * time.Minute),
+			payloadBytes: payloadBytes,
+			held:         held,
+		}
+	}
+	maxPayloadBytes := resource.MustParse("250")
+
+	// Captures sorted from the oldest, like listRetainedCaptures returns them
+	captures := []retainedCapture{
+		newCapture("a-1", "uid-a", 0, 100, false),
+		newCapture("b-1", "uid-b", 1, 100, false),
+		newCapture("a-2", "uid-a", 2, 100, false),
+		newCapture("a-3", "uid-a", 3, 100, false),
+		newCapture("b-2", "uid-b", 4, 100, false),
+	}
+	heldCaptures := []retainedCapture{
+		newCapture("a-1", "uid-a", 0, 100, true),
+		newCapture("b-1", "uid-b", 1, 100, false),
+		newCapture("a-2", "uid-a", 2, 100, false),
+		newCapture("a-3", "uid-a", 3, 100, false),
+		newCapture("b-2", "uid-b", 4, 100, false),
+	}
+
+	tests := []struct {
+		name      string
+		captures  []retainedCapture
+		retention kuberecoveryv1alpha1.RetentionT
+		expected  map[string]bool
+	}{
+		{
+			name:      "no limits",
+			captures:  captures,
+			retention: kuberecoveryv1alpha1.RetentionT{Period: "24h"},
+			expected:  map[string]bool{},
+		},
+		{
+			name:      "oldest captures over the limit of each object",
+			captures:  captures,
+			retention: kuberecoveryv1alpha1.RetentionT{MaxCapturesPerObject: 1},
+			expected:  map[string]bool{"a-1": true, "a-2": true, "b-1": true},
+		},
+		{
+			name:      "oldest captures over the limit of the RecoveryConfig",
+			captures:  captures,
+			retention: kuberecoveryv1alpha1.RetentionT{MaxCaptures: 3},
+			expected:  map[string]bool{"a-1": true, "b-1": true},
+		},
+		{
+			name:      "oldest captures over the payload size",
+			captures:  captures,
+			retention: kuberecoveryv1alpha1.RetentionT{MaxPayloadBytes: &maxPayloadBytes},
+			expected:  map[string]bool{"a-1": true, "b-1": true, "a-2": true},
+		},
+		{
+			name:      "limits of the RecoveryConfig applied after the ones of each object",
+			captures:  captures,
+			retention: kuberecoveryv1alpha1.RetentionT{MaxCapturesPerObject: 2, MaxCaptures: 3},
+			expected:  map[string]bool{"a-1": true, "b-1": true},
+		},
+		{
+			name:      "held captures counted but not evicted",
+			captures:  heldCaptures,
+			retention: kuberecoveryv1alpha1.RetentionT{MaxCapturesPerObject: 1, MaxCaptures: 2},
+			expected:  map[string]bool{"a-2": true, "a-3": true, "b-1": true},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if evicted := selectEvictedCaptures(test.captures, test.retention); !reflect.DeepEqual(evicted,
+				test.expected) {
+				t.Errorf("expected evicted captures %v, got %v", test.expected, evicted)
+			}
+		})
+	}
+}
+
+func TestGetRuleRetention(t *testing.T) {
+	recoveryConfig := &kuberecoveryv1alpha1.RecoveryConfig{
+		Spec: kuberecoveryv1alpha1.RecoveryConfigSpec{
+			ResourcesIncluded: []kuberecoveryv1alpha1.GvrResourceT{
+				{
+					APIVersion: "v1",
+					Resources:  []string{"configmaps"},
+					Retention:  "30d",
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"tier": "production"},
+					},
+				},
+				{
+					APIVersion: "v1",
+					Resources:  []string{"configmaps"},
+					Namespaces: []string{"*"},
+					Retention:  "1d",
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name              string
+		namespace         string
+		namespaceLabels   map[string]string
+		expectedRetention string
+	}{
+		{
+			name:              "namespace selected by the namespace selector",
+			namespace:         "shop",
+			namespaceLabels:   map[string]string{"tier": "production"},
+			expectedRetention: "30d",
+		},
+		{
+			name:              "namespace not selected by the namespace selector",
+			namespace:         "shop",
+			namespaceLabels:   map[string]string{"tier": "staging"},
+			expectedRetention: "1d",
+		},
+		{
+			name:              "namespace deleted with its resources",
+			namespace:         "shop",
+			expectedRetention: "30d",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatalf("unexpected error building the scheme: %v", err)
+			}
+			clientBuilder := fake.NewClientBuilder().WithScheme(scheme)
+			if test.namespaceLabels != nil {
+				clientBuilder = clientBuilder.WithObjects(&corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{Name: test.namespace, Labels: test.namespaceLabels},
+				})
+			}
+			reconciler := &RecoveryConfigReconciler{Client: clientBuilder.Build(), Scheme: scheme}
+
+			obj := &unstructured.Unstructured{}
+			obj.SetAPIVersion("v1")
+			obj.SetKind("ConfigMap")
+			obj.SetNamespace(test.namespace)
+			obj.SetName("settings")
+
+			retention := reconciler.getRuleRetention(context.Background(), obj, "configmaps", recoveryConfig)
+			if retention != test.expectedRetention {
+				t.Errorf("expected retention %s, got %s", test.expectedRetention, retention)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/globals"
+)
+
+const (
+	recoveryConfigValidationWebhookPath = "/validate-kuberecovery-freepik-com-v1alpha1-recoveryconfig"
+)
+
+// +kubebuilder:webhook:path=/validate-kuberecovery-freepik-com-v1alpha1-recoveryconfig,mutating=false,failurePolicy=fail,sideEffects=None,groups=kuberecovery.freepik.com,resources=recoveryconfigs,verbs=create;update,versions=v1alpha1,name=vrecoveryconfig.kuberecovery.freepik.com,admissionReviewVersions=v1,timeoutSeconds=10
+
+// recoveryConfigValidator rejects the RecoveryConfigs with mistakes that would only surface at runtime: invalid
+// retention periods, selectors or exclusion patterns, and included resources that are not served by the cluster.
+// Overlaps with other RecoveryConfigs are allowed, but returned as warnings
+type recoveryConfigValidator struct {
+	reconciler *RecoveryConfigReconciler
+}
+
+// ValidateCreate validates the RecoveryConfig being created
+func (v *recoveryConfigValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	recoveryConfig, ok := obj.(*kuberecoveryv1alpha1.RecoveryConfig)
+	if !ok {
+		return nil, fmt.Errorf(unexpectedAdmissionObjectError, obj)
+	}
+	return v.validate(ctx, recoveryConfig)
+}
+
+// ValidateUpdate validates the RecoveryConfig being updated. Changes that keep the spec, like the ones of the finalizers,
+// are not validated, so a resource removed from the cluster never blocks them
+func (v *recoveryConfigValidator) ValidateUpdate(ctx context.Context,
+	oldObj, newObj runtime.Object) (admission.Warnings, error) {
+
+	oldRecoveryConfig, ok := oldObj.(*kuberecoveryv1alpha1.RecoveryConfig)
+	if !ok {
+		return nil, fmt.Errorf(unexpectedAdmissionObjectError, oldObj)
+	}
+	recoveryConfig, ok := newObj.(*kuberecoveryv1alpha1.RecoveryConfig)
+	if !ok {
+		return nil, fmt.Errorf(unexpectedAdmissionObjectError, newObj)
+	}
+
+	if !recoveryConfig.DeletionTimestamp.IsZero() || reflect.DeepEqual(oldRecoveryConfig.Spec, recoveryConfig.Spec) {
+		return nil, nil
+	}
+	return v.validate(ctx, recoveryConfig)
+}
+
+// ValidateDelete allows every deletion, as the deletion policy is applied by the controller
+func (v *recoveryConfigValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validate checks the spec of the RecoveryConfig, returning every invalid field at once
+func (v *recoveryConfigValidator) validate(ctx context.Context,
+	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) (admission.Warnings, error) {
+
+	specPath := field.NewPath("spec")
+	spec := recoveryConfig.Spec
+
+	var errs field.ErrorList
+	errs = append(errs, validateRetentionPeriod(specPath.Child("retention", "period"), spec.Retention.Period)...)
+	if spec.Retention.MaxObjectRetention != "" {
+		errs = append(errs, validateRetentionPeriod(specPath.Child("retention", "maxObjectRetention"),
+			spec.Retention.MaxObjectRetention)...)
+	}
+	if spec.OrphanRetention != "" {
+		errs = append(errs, validateRetentionPeriod(specPath.Child("orphanRetention"), spec.OrphanRetention)...)
+	}
+
+	warnings, includedErrs := validateResourcesIncluded(specPath.Child("resourcesIncluded"), spec.ResourcesIncluded)
+	errs = append(errs, includedErrs...)
+	errs = append(errs, validateResourcesExcluded(specPath.Child("resourcesExcluded"), spec.ResourcesExcluded)...)
+
+	if len(errs) > 0 {
+		return warnings, apierrors.NewInvalid(kuberecoveryv1alpha1.GroupVersion.WithKind("RecoveryConfig").GroupKind(),
+			recoveryConfig.Name, errs)
+	}
+
+	overlaps, err := v.getOverlaps(ctx, recoveryConfig)
+	if err != nil {
+		return warnings, err
+	}
+
+	return append(warnings, overlaps...), nil
+}
+
+// validateRetentionPeriod checks the period is a positive duration, as parsed by the controllers
+func validateRetentionPeriod(path *field.Path, period string) field.ErrorList {
+	duration, err := parseDurationWithDays(period)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, period, fmt.Sprintf(invalidRetentionPeriodError, err))}
+	}
+	if duration <= 0 {
+		return field.ErrorList{field.Invalid(path, period, fmt.Sprintf(invalidRetentionPeriodError, "not positive"))}
+	}
+	return nil
+}
+
+// validateResourcesIncluded resolves the resources included through discovery, as an informer is created for each of
+// them. Cluster-scoped resources can not be filtered by namespace. The apiVersions that can not be resolved because
+// discovery fails are returned as warnings, so an unavailable aggregated API does not block the RecoveryConfigs
+func validateResourcesIncluded(path *field.Path,
+	included []kuberecoveryv1alpha1.GvrResourceT) (warnings admission.Warnings, errs field.ErrorList) {
+
+	discoveryClient := globals.Application.KubeRawCoreClient.Discovery()
+	served := make(map[string]*metav1.APIResourceList)
+
+	for i, rule := range included {
+		rulePath := path.Index(i)
+
+		if rule.Retention != "" {
+			errs = append(errs, validateRetentionPeriod(rulePath.Child("retention"), rule.Retention)...)
+		}
+		errs = append(errs, validateLabelSelector(rulePath.Child("labelSelector"), rule.LabelSelector)...)
+		errs = append(errs, validateLabelSelector(rulePath.Child("namespaceSelector"), rule.NamespaceSelector)...)
+
+		resources, resolved := served[rule.APIVersion]
+		if !resolved {
+			var err error
+			resources, err = discoveryClient.ServerResourcesForGroupVersion(rule.APIVersion)
+			if apierrors.IsNotFound(err) {
+				errs = append(errs, field.NotFound(rulePath.Child("apiVersion"), rule.APIVersion))
+				continue
+			}
+			if err != nil {
+				warnings = append(warnings, fmt.Sprintf(apiVersionNotResolvedMessage, rule.APIVersion, err))
+				continue
+			}
+			served[rule.APIVersion] = resources
+		}
+
+		for j, resource := range rule.Resources {
+			resourcePath := rulePath.Child("resources").Index(j)
+
+			index := slices.IndexFunc(resources.APIResources, func(apiResource metav1.APIResource) bool {
+				return apiResource.Name == resource
+			})
+			if index < 0 {
+				errs = append(errs, field.Invalid(resourcePath, resource,
+					fmt.Sprintf(resourceNotServedError, rule.APIVersion)))
+				continue
+			}
+
+			apiResource := resources.APIResources[index]
+			if !slices.Contains(apiResource.Verbs, "list") || !slices.Contains(apiResource.Verbs, "watch") {
+				errs = append(errs, field.Invalid(resourcePath, resource, resourceNotWatchableError))
+			}
+			if !apiResource.Namespaced && !watchesAllNamespaces(rule.Namespaces) {
+				errs = append(errs, field.Invalid(rulePath.Child("namespaces"), rule.Namespaces,
+					fmt.Sprintf(clusterScopedNamespacesError, resource)))
+			}
+			if !apiResource.Namespaced && rule.NamespaceSelector != nil {
+				errs = append(errs, field.Invalid(rulePath.Child("namespaceSelector"), metav1.FormatLabelSelector(rule.NamespaceSelector),
+					fmt.Sprintf(clusterScopedNamespacesError, resource)))
+			}
+		}
+	}
+
+	return warnings, errs
+}
+
+// validateLabelSelector checks the label selector can be converted to the selector used to list the resources
+func validateLabelSelector(path *field.Path, selector *metav1.LabelSelector) field.ErrorList {
+	if selector == nil {
+		return nil
+	}
+	_, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return field.ErrorList{field.Invalid(path, metav1.FormatLabelSelector(selector), err.Error())}
+	}
+	return nil
+}
+
+// validateResourcesExcluded compiles the regular expressions of the resources, namespaces and names excluded
+func validateResourcesExcluded(path *field.Path, excluded []kuberecoveryv1alpha1.GvrResourceT) (errs field.ErrorList) {
+	for i, rule := range excluded {
+		rulePath := path.Index(i)
+
+		for _, patterns := range []struct {
+			child  string
+			values []string
+		}{
+			{"resources", rule.Resources},
+			{"namespaces", rule.Namespaces},
+			{"names", rule.Names},
+		} {
+			for j, pattern := range patterns.values {
+				if pattern == "*" {
+					continue
+				}
+				_, err := regexp.Compile(pattern)
+				if err != nil {
+					errs = append(errs, field.Invalid(rulePath.Child(patterns.child).Index(j), pattern, err.Error()))
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// getOverlaps returns a warning for every resource included by the RecoveryConfig that is included by other
+// RecoveryConfigs too in the same namespaces, so it is captured more than once. Namespace selectors are compared
+// by the namespaces they select now
+func (v *recoveryConfigValidator) getOverlaps(ctx context.Context,
+	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) (warnings admission.Warnings, err error) {
+
+	recoveryConfigs := &kuberecoveryv1alpha1.RecoveryConfigList{}
+	err = v.reconciler.List(ctx, recoveryConfigs)
+	if err != nil {
+		return nil, fmt.Errorf(listRecoveryConfigsError, err)
+	}
+
+	for _, rule := range recoveryConfig.Spec.ResourcesIncluded {
+		ruleNamespaces, err := v.reconciler.getWatchedNamespaces(ctx, rule)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, other := range recoveryConfigs.Items {
+			if other.Name == recoveryConfig.Name {
+				continue
+			}
+
+			for _, otherRule := range other.Spec.ResourcesIncluded {
+				if rule.APIVersion != otherRule.APIVersion {
+					continue
+				}
+				otherNamespaces, err := v.reconciler.getWatchedNamespaces(ctx, otherRule)
+				if err != nil {
+					continue
+				}
+
+				for _, resource := range rule.Resources {
+					if !slices.Contains(otherRule.Resources, resource) {
+						continue
+					}
+					namespaces, overlapped := getNamespacesOverlap(ruleNamespaces, otherNamespaces)
+					if overlapped {
+						warnings = append(warnings, fmt.Sprintf(recoveryConfigOverlapMessage, rule.APIVersion,
+							resource, namespaces, other.Name))
+					}
+				}
+			}
+		}
+	}
+
+	return warnings, nil
+}
+
+// getNamespacesOverlap returns the namespaces watched by both rules, described for the overlap warnings.
+// The "" namespace watches all of them
+func getNamespacesOverlap(namespaces, otherNamespaces []string) (string, bool) {
+	allNamespaces := slices.Contains(namespaces, "")
+	otherAllNamespaces := slices.Contains(otherNamespaces, "")
+
+	switch {
+	case allNamespaces && otherAllNamespaces:
+		return "all namespaces", true
+	case allNamespaces:
+		return fmt.Sprintf("namespaces %v", otherNamespaces), len(otherNamespaces) > 0
+	case otherAllNamespaces:
+		return fmt.Sprintf("namespaces %v", namespaces), len(namespaces) > 0
+	}
+
+	var shared []string
+	for _, namespace := range namespaces {
+		if slices.Contains(otherNamespaces, namespace) {
+			shared = append(shared, namespace)
+		}
+	}
+	return fmt.Sprintf("namespaces %v", shared), len(shared) > 0
+}
@@ -18,17 +18,25 @@ package controller
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
-	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
@@ -52,25 +60,63 @@ func (r *RecoveryConfigReconciler) Watch(ctx context.Context, eventType watch.Ev
 
 	newInformers := make(map[string]bool)
 
+	// Prefixes of the keys of the rules that can not be synced, whose informers are kept until they are fixed
+	keptInformers := []string{}
+
 	// For each GVR included in the ResourcesIncluded section of the RecoveryConfig, we create an informer to watch
 	// delete events on the resources
 	// GV = GroupVersion (APIVersion) is a string, so just one group by ResourceIncluded is allowed
 	// R = Resources is a string array, so multiple resources by ResourceIncluded is allowed
 	// N = Namespace is a string array, so multiple namespaces by ResourceIncluded is allowed
+	// Rules with invalid selectors are skipped, so the informers of the other rules are still synced
 	for _, res := range resource.Spec.ResourcesIncluded {
+		// The label selector is pushed down to the informers, so they only list and watch the matching resources
+		var labelSelector labels.Selector
+		selectorKey := ""
+		if res.LabelSelector != nil {
+			var selectorErr error
+			labelSelector, selectorErr = metav1.LabelSelectorAsSelector(res.LabelSelector)
+			if selectorErr != nil {
+				err = fmt.Errorf(parseLabelSelectorError, res.APIVersion, selectorErr)
+				keptInformers = append(keptInformers, getRuleWatcherKeyPrefixes(resource.Name, res)...)
+				continue
+			}
+			selectorKey = labelSelector.String()
+		}
+
+		// The namespace selector is applied to the events of a single informer watching all namespaces,
+		// so the informers do not follow the namespaces as they are created or relabelled
+		var namespaceSelector labels.Selector
+		if res.NamespaceSelector != nil {
+			var selectorErr error
+			namespaceSelector, selectorErr = metav1.LabelSelectorAsSelector(res.NamespaceSelector)
+			if selectorErr != nil {
+				err = fmt.Errorf(parseLabelSelectorError, res.APIVersion, selectorErr)
+				keptInformers = append(keptInformers, getRuleWatcherKeyPrefixes(resource.Name, res)...)
+				continue
+			}
+		}
+
+		// Namespaces are the ones listed in the rule. If no namespace is specified, the wildcard is used or they
+		// are selected by labels, we watch all namespaces
+		namespaces := res.Namespaces
+		if namespaceSelector != nil || watchesAllNamespaces(res.Namespaces) {
+			namespaces = []string{""}
+		}
+
 		// Resources must be an array so, for each resource, we create an informer
 		for _, rsc := range res.Resources {
-			// If no namespace is specified or the wildcard is used, we watch all namespaces
-			namespaces := res.Namespaces
-			if len(namespaces) == 0 || (len(namespaces) == 1 && namespaces[0] == "*") {
-				namespaces = []string{""}
-			}
 
 			// For each namespace, we create an informer
 			for _, ns := range namespaces {
 
-				// Key to store the informer in the pool
-				resourceWatcherKey := fmt.Sprintf(pools.ResourceWatcherPoolKeyFormat, resource.Name, res.APIVersion, rsc, ns)
+				// Key to store the informer in the pool, different for every label and namespace selector
+				keyNamespace := ns
+				if namespaceSelector != nil {
+					keyNamespace = fmt.Sprintf(namespaceSelectorKeyFormat, namespaceSelector.String())
+				}
+				resourceWatcherKey := fmt.Sprintf(pools.ResourceWatcherPoolKeyFormat, resource.Name, res.APIVersion, rsc,
+					keyNamespace, selectorKey)
 
 				// Store the informer in the newInformers map to check if it is already in the pool
 				newInformers[resourceWatcherKey] = true
@@ -111,6 +157,9 @@ func (r *RecoveryConfigReconciler) Watch(ctx context.Context, eventType watch.Ev
 						Resource:       rsc,
 						Namespace:      ns,
 						Chan:           make(chan struct{}),
+						LabelSelector:  labelSelector,
+
+						NamespaceSelector: namespaceSelector,
 					}
 
 					// Add the resource watcher to the pool and create the informer
@@ -128,6 +177,12 @@ func (r *RecoveryConfigReconciler) Watch(ctx context.Context, eventType watch.Ev
 	// If it is not in the new resources list, we stop the informer and remove it from the pool
 	for key, watcher := range existingInformers {
 		if resource.Name == watcher.RecoveryConfig.Name {
+			kept := slices.ContainsFunc(keptInformers, func(prefix string) bool {
+				return strings.HasPrefix(key, prefix)
+			})
+			if kept && eventType != watch.Deleted {
+				continue
+			}
 			if _, exists := newInformers[key]; !exists {
 				logger.Info(fmt.Sprintf(stopWatchingResourceMessage, watcher.APIVersion, watcher.Resource, watcher.Namespace))
 				close(watcher.Chan)
@@ -136,7 +191,88 @@ func (r *RecoveryConfigReconciler) Watch(ctx context.Context, eventType watch.Ev
 		}
 	}
 
-	return nil
+	return err
+}
+
+// getWatchedNamespaces returns the namespaces included by the rule: the ones selected now by its namespace selector,
+// the ones listed in it or just "", which includes all of them
+func (r *RecoveryConfigReconciler) getWatchedNamespaces(ctx context.Context,
+	rule kuberecoveryv1alpha1.GvrResourceT) ([]string, error) {
+
+	if rule.NamespaceSelector == nil {
+		if watchesAllNamespaces(rule.Namespaces) {
+			return []string{""}, nil
+		}
+		return rule.Namespaces, nil
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(rule.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf(parseLabelSelectorError, rule.APIVersion, err)
+	}
+
+	namespaceList := &corev1.NamespaceList{}
+	err = r.List(ctx, namespaceList, client.MatchingLabelsSelector{Selector: selector})
+	if err != nil {
+		return nil, fmt.Errorf(listNamespacesError, err)
+	}
+
+	namespaces := make([]string, 0, len(namespaceList.Items))
+	for _, namespace := range namespaceList.Items {
+		namespaces = append(namespaces, namespace.Name)
+	}
+	return namespaces, nil
+}
+
+// watchesAllNamespaces returns true if the namespaces of a rule include every namespace
+func watchesAllNamespaces(namespaces []string) bool {
+	return len(namespaces) == 0 || (len(namespaces) == 1 && namespaces[0] == "*")
+}
+
+// getRuleWatcherKeyPrefixes returns the prefixes of the keys of the informers of the rule of the RecoveryConfig
+func getRuleWatcherKeyPrefixes(recoveryConfigName string, rule kuberecoveryv1alpha1.GvrResourceT) []string {
+	prefixes := make([]string, 0, len(rule.Resources))
+	for _, rsc := range rule.Resources {
+		prefixes = append(prefixes, fmt.Sprintf("%s/%s/%s/", recoveryConfigName, rule.APIVersion, rsc))
+	}
+	return prefixes
+}
+
+// isNamespaceSelected returns true if the namespace selector, if any, selects the namespace. Namespaces are read from
+// the cache of the manager, as the events of every namespace are filtered by them. A namespace not found anymore is
+// selected: when a whole namespace is deleted, the deletion of its last resources can be seen after the namespace
+// is gone, and its labels are unknown then, so they are captured instead of losing them
+func (r *RecoveryConfigReconciler) isNamespaceSelected(ctx context.Context, namespaceSelector labels.Selector,
+	namespace string) bool {
+
+	if namespaceSelector == nil {
+		return true
+	}
+	if namespace == "" {
+		return false
+	}
+
+	liveNamespace := &corev1.Namespace{}
+	err := r.Get(ctx, types.NamespacedName{Name: namespace}, liveNamespace)
+	if apierrors.IsNotFound(err) {
+		return true
+	}
+	if err != nil {
+		log.FromContext(ctx).Info(fmt.Sprintf(getNamespaceError, namespace, err))
+		return false
+	}
+	return namespaceSelector.Matches(labels.Set(liveNamespace.GetLabels()))
+}
+
+// isResourceRelabelled returns true if the resource deleted from an informer with a label selector still exists,
+// as the informers see the resources that stop matching their selector as deleted
+func isResourceRelabelled(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) bool {
+	live, err := globals.Application.KubeRawClient.Resource(gvr).Namespace(obj.GetNamespace()).
+		Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		return false
+	}
+	return live.GetUID() == obj.GetUID()
 }
 
 // createInformer creates an informer for the resource specified in the resourceWatcher
@@ -160,20 +296,66 @@ func (r *RecoveryConfigReconciler) createInformer(ctx context.Context, resourceW
 		Resource: resourceWatcher.Resource,
 	}
 
+	// Filter the resources listed and watched by the label selector of the resourceWatcher, if any
+	var tweakListOptions dynamicinformer.TweakListOptionsFunc
+	if resourceWatcher.LabelSelector != nil {
+		labelSelector := resourceWatcher.LabelSelector.String()
+		tweakListOptions = func(options *metav1.ListOptions) {
+			options.LabelSelector = labelSelector
+		}
+	}
+
 	// Creates the informer factory for the resource and the namespaces specified in the resourceWatcher
 	// using the global dynamic client defined for the operator
 	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(
 		globals.Application.KubeRawClient,
 		0,
 		resourceWatcher.Namespace,
-		nil,
+		tweakListOptions,
 	)
 
 	// Creates the informer for the gvr defined
 	informer := factory.ForResource(*gvr).Informer()
 
-	// Add event handler to the informer and listen for delete events
+	// Add event handler to the informer and listen for update and delete events
 	_, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		// Listen for update events to save the previous state of the resource as a revision
+		UpdateFunc: func(oldObj, newObj interface{}) {
+
+			// Get the recoveryConfig from the pool
+			watchedResource, exists := r.ResourceWatcherPool.Get(resourceWatcherKey)
+			if !exists {
+				logger.Info(fmt.Sprintf(recoveryConfigNotExistsInPoolError, resourceWatcherKey))
+				return
+			}
+			recoveryConfig := watchedResource.RecoveryConfig
+
+			// Revisions are only captured when the RecoveryConfig opts into it
+			if !recoveryConfig.Spec.RevisionHistory.Enabled {
+				return
+			}
+
+			// Get the previous and the current state as unstructured objects
+			oldUnstructuredObj, ok := oldObj.(*unstructured.Unstructured)
+			if !ok {
+				logger.Info(fmt.Sprintf(convertToUnstructuredError, oldObj))
+				return
+			}
+			newUnstructuredObj, ok := newObj.(*unstructured.Unstructured)
+			if !ok {
+				logger.Info(fmt.Sprintf(convertToUnstructuredError, newObj))
+				return
+			}
+
+			// Save the previous state as RecoveryResource when the resource really changed
+			// in a namespace selected by the watcher
+			if !hasResourceChanged(oldUnstructuredObj, newUnstructuredObj) ||
+				!r.isNamespaceSelected(ctx, watchedResource.NamespaceSelector, oldUnstructuredObj.GetNamespace()) {
+				return
+			}
+			r.captureUpdatedResource(ctx, oldUnstructuredObj.DeepCopy(), recoveryConfig)
+		},
+
 		// Listen for delete events
 		DeleteFunc: func(obj interface{}) {
 
@@ -185,6 +367,15 @@ func (r *RecoveryConfigReconciler) createInformer(ctx context.Context, resourceW
 			}
 			recoveryConfig := watchedResource.RecoveryConfig
 
+			// When the informer misses the deletion event (watch gaps, relists...), it delivers a tombstone
+			// with the last state of the object known by the cache. Unwrap it and mark the capture as stale
+			opts := captureOptions{}
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				logger.Info(fmt.Sprintf(tombstoneReceivedMessage, tombstone.Key))
+				obj = tombstone.Obj
+				opts.staleCache = true
+			}
+
 			// Get the object deleted as unstructured object
 			unstructuredObj, ok := obj.(*unstructured.Unstructured)
 			if !ok {
@@ -192,59 +383,30 @@ func (r *RecoveryConfigReconciler) createInformer(ctx context.Context, resourceW
 				return
 			}
 
-			// Get the resource name from the group, version and kind
-			resource, err := getResourceFromKind(unstructuredObj.GroupVersionKind().Group,
-				unstructuredObj.GroupVersionKind().Version, unstructuredObj.GroupVersionKind().Kind)
-			if err != nil {
-				logger.Info(getResourceFromKindError, err)
-				return
+			// Deletions admitted by the deletion capture webhook are captured here, and the state it received
+			// replaces the last one known by the cache, as it is the one the resource had when it was deleted
+			if r.pendingDeletions != nil {
+				pending := r.pendingDeletions.take(recoveryConfig.Name, unstructuredObj.GetUID())
+				if pending != nil && opts.staleCache {
+					unstructuredObj = pending.obj
+					opts.staleCache = false
+				}
 			}
 
-			// Check if the resource is excluded to save it as RecoveryResource
-			for _, excluded := range recoveryConfig.Spec.ResourcesExcluded {
-				for _, excludedResource := range excluded.Resources {
-					for _, excludedNamespace := range excluded.Namespaces {
-						for _, excludedName := range excluded.Names {
-
-							// resource and namespace can be regex
-							resourceMatched, err := regexp.MatchString(excludedResource, resource)
-							if err != nil {
-								logger.Info(fmt.Sprintf(regexResourceError, resource, err))
-								return
-							}
-							namespaceMatched, err := regexp.MatchString(excludedNamespace, unstructuredObj.GetNamespace())
-							if err != nil {
-								logger.Info(fmt.Sprintf(regexNamespaceError, unstructuredObj.GetNamespace(), err))
-								return
-							}
-							nameMatched, err := regexp.MatchString(excludedName, unstructuredObj.GetName())
-							if err != nil {
-								logger.Info(fmt.Sprintf(regexNameError, unstructuredObj.GetName(), err))
-								return
-							}
-
-							// Check if the resource is excluded, if true we do not save it as RecoveryResource
-							if excluded.APIVersion == unstructuredObj.GetAPIVersion() && resourceMatched && namespaceMatched && nameMatched {
-								logger.Info(fmt.Sprintf(resourceExcludedFromRecoveryMessage, unstructuredObj.GetAPIVersion(),
-									resource, unstructuredObj.GetNamespace()))
-								return
-							}
-						}
-					}
-				}
+			// Resources in the namespaces not selected by the namespace selector are not captured
+			if !r.isNamespaceSelected(ctx, watchedResource.NamespaceSelector, unstructuredObj.GetNamespace()) {
+				return
 			}
 
-			// Save the resource as RecoveryResource
-			recoveryResourceName, err := r.saveRecoveryResource(ctx, unstructuredObj, recoveryConfig)
-			if err != nil {
-				logger.Info(fmt.Sprintf(saveRecoveryResourceError, unstructuredObj.GetAPIVersion(), resource,
-					unstructuredObj.GetNamespace(), unstructuredObj.GetName(), err))
+			// Resources that stop matching the label selector are not deleted, so they are not captured
+			if resourceWatcher.LabelSelector != nil && isResourceRelabelled(ctx, *gvr, unstructuredObj) {
+				logger.Info(fmt.Sprintf(resourceRelabelledMessage, unstructuredObj.GetAPIVersion(), gvr.Resource,
+					unstructuredObj.GetNamespace(), unstructuredObj.GetName()))
 				return
 			}
-			logger.Info(fmt.Sprintf(recoveryResourceSavedMessage,
-				unstructuredObj.GetAPIVersion(), unstructuredObj.GetKind(), unstructuredObj.GetNamespace(),
-				unstructuredObj.GetName(), recoveryResourceName))
 
+			// Save the resource as RecoveryResource unless it is excluded or already captured
+			r.captureDeletedResource(ctx, unstructuredObj, recoveryConfig, opts)
 		},
 	})
 	if err != nil {
@@ -257,19 +419,27 @@ func (r *RecoveryConfigReconciler) createInformer(ctx context.Context, resourceW
 
 // saveRecoveryResource saves the resource deleted as RecoveryResource in the cluster
 func (r *RecoveryConfigReconciler) saveRecoveryResource(ctx context.Context, obj *unstructured.Unstructured,
-	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) (recoveryResourceName string, err error) {
+	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig, opts captureOptions) (recoveryResourceName string, err error) {
 
-	// Get the retention time for the RecoveryResource created and parse it
-	retentionPeriod := recoveryConfig.Spec.Retention.Period
+	// Get the retention time for the RecoveryResource created from the most specific value and parse it
+	retentionPeriod, retentionSource := r.getRetentionPeriod(ctx, obj, opts.resource, recoveryConfig)
 	parsedRetentionPeriod, err := parseDurationWithDays(retentionPeriod)
 	if err != nil {
 		return recoveryResourceName, fmt.Errorf(timeParseError, err)
 	}
 
-	// Create the labels for the RecoveryResource: Name, savedAt and retainUntil
+	// Keep the UID and creation time of the resource before removing them from the metadata
+	sourceUID := string(obj.GetUID())
+	sourceCreationTimestamp := obj.GetCreationTimestamp()
+
+	// Create the labels for the RecoveryResource: Name, savedAt and retainUntil.
+	// Revisions of the same resource can be saved in the same second, so the revision is part of their name
 	now := metav1.Now().UTC()
 	recoveryResourceName = fmt.Sprintf(recoveryResourceNameFormat, recoveryConfig.Name, strings.ToLower(obj.GetKind()),
 		obj.GetName(), now.Format(timeParseFormatName))
+	if opts.revision > 0 {
+		recoveryResourceName = fmt.Sprintf(recoveryResourceRevisionNameFormat, recoveryResourceName, opts.revision)
+	}
 	savedAt := now.Format(timeParseFormat)
 	retainUntil := now.Add(parsedRetentionPeriod).Format(timeParseFormat)
 
@@ -277,21 +447,65 @@ func (r *RecoveryConfigReconciler) saveRecoveryResource(ctx context.Context, obj
 	for _, field := range fieldsExcludedFromMetadataRecoveryResource {
 		unstructured.RemoveNestedField(obj.Object, "metadata", field)
 	}
+	applyCaptureSanitizers(obj, recoveryConfig.Spec.Sanitizers)
+
+	labels := map[string]interface{}{
+		recoveryResourceSavedAtLabel:        savedAt,
+		recoveryResourceRetainUntilLabel:    retainUntil,
+		recoveryResourceRecoveryConfigLabel: recoveryConfig.Name,
+		recoveryResourceSourceUIDLabel:      sourceUID,
+		recoveryResourceCaptureReasonLabel:  opts.reason,
+	}
+	if opts.revision > 0 {
+		labels[recoveryResourceRevisionLabel] = strconv.Itoa(opts.revision)
+	}
+
+	metadata := map[string]interface{}{
+		"name":   recoveryResourceName,
+		"labels": labels,
+	}
+
+	annotations := map[string]interface{}{
+		recoveryResourceRetentionPeriodAnnotation: retentionPeriod,
+		recoveryResourceRetentionSourceAnnotation: retentionSource,
+	}
+	if !sourceCreationTimestamp.IsZero() {
+		annotations[recoveryResourceSourceCreationAnnotation] = sourceCreationTimestamp.UTC().Format(time.RFC3339)
+	}
+	if opts.staleCache {
+		annotations[recoveryResourceStaleCacheAnnotation] = recoveryResourceStaleCacheAnnotationValue
+	}
+
+	// The size of the payload is recorded, as it counts against the retention limits of the RecoveryConfig
+	payload, err := json.Marshal(obj.Object)
+	if err != nil {
+		return recoveryResourceName, fmt.Errorf(serializingPayloadError, err)
+	}
+	annotations[recoveryResourcePayloadSizeAnnotation] = strconv.Itoa(len(payload))
+
+	// When an external storage backend is configured, the payload is stored there and the spec
+	// just keeps the identity of the resource, so the whole object does not live in etcd
+	spec := obj.Object
+	payloadRef := ""
+	if r.StorageBackend != nil {
+		payloadRef, err = r.StorageBackend.Put(ctx, fmt.Sprintf(payloadKeyFormat, recoveryResourceName), payload)
+		if err != nil {
+			return recoveryResourceName, fmt.Errorf(storePayloadError, recoveryResourceName, err)
+		}
+
+		spec = getPayloadIdentity(obj)
+		annotations[recoveryResourcePayloadRefAnnotation] = payloadRef
+	}
+
+	metadata["annotations"] = annotations
 
 	// Create the RecoveryResource object
 	recoveryObj := &unstructured.Unstructured{
 		Object: map[string]interface{}{
 			"apiVersion": kuberecoveryv1alpha1.GroupVersion.String(),
 			"kind":       recoveryResourceType,
-			"metadata": map[string]interface{}{
-				"name": recoveryResourceName,
-				"labels": map[string]interface{}{
-					recoveryResourceSavedAtLabel:        savedAt,
-					recoveryResourceRetainUntilLabel:    retainUntil,
-					recoveryResourceRecoveryConfigLabel: recoveryConfig.Name,
-				},
-			},
-			"spec": obj.Object,
+			"metadata":   metadata,
+			"spec":       spec,
 		},
 	}
 
@@ -308,6 +522,10 @@ func (r *RecoveryConfigReconciler) saveRecoveryResource(ctx context.Context, obj
 	// Save the RecoveryResource in the cluster
 	_, err = dynamicClient.Create(ctx, recoveryObj, metav1.CreateOptions{})
 	if err != nil {
+		// Do not leave orphan payloads in the storage backend
+		if payloadRef != "" {
+			_ = r.StorageBackend.Delete(ctx, payloadRef)
+		}
 		return recoveryResourceName, fmt.Errorf(recoveryResourceCreationError, recoveryObj.GetName(), err)
 	}
 
@@ -0,0 +1,374 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/globals"
+	"freepik.com/kuberecovery/internal/pools"
+)
+
+const (
+	deletionCaptureWebhookPath = "/validate-delete-capture"
+	deletionCaptureWebhookName = "vdeletecapture.kuberecovery.freepik.com"
+)
+
+// The rules of the deletion capture webhook are narrowed by the operator to the resources included by the
+// RecoveryConfigs, so the ones below are just the initial rules of the generated manifests
+// +kubebuilder:webhook:path=/validate-delete-capture,mutating=false,failurePolicy=ignore,sideEffects=NoneOnDryRun,groups=*,resources=*,verbs=delete,versions=*,name=vdeletecapture.kuberecovery.freepik.com,admissionReviewVersions=v1,timeoutSeconds=5
+
+// deletionCaptureWebhook queues the resources watched by the RecoveryConfigs before the API server deletes them.
+// It closes the gaps of the informers: deletions done while they are not synced or the operator is restarting.
+// Deletions are never rejected, and they are not captured in the request, as the deletion may still fail: the
+// informers capture the queued state when they see the deletion, and the deletions they miss are captured once the
+// resource is gone
+type deletionCaptureWebhook struct {
+	reconciler *RecoveryConfigReconciler
+}
+
+// pendingDeletion is a deletion admitted by the deletion capture webhook, waiting to be captured for a RecoveryConfig
+type pendingDeletion struct {
+	obj            *unstructured.Unstructured
+	recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig
+	admittedAt     time.Time
+}
+
+// pendingDeletionQueue keeps the deletions admitted by the deletion capture webhook until they are captured
+type pendingDeletionQueue struct {
+	mutex sync.Mutex
+	items map[string]*pendingDeletion
+}
+
+// newPendingDeletionQueue returns an empty queue of pending deletions
+func newPendingDeletionQueue() *pendingDeletionQueue {
+	return &pendingDeletionQueue{items: map[string]*pendingDeletion{}}
+}
+
+// add queues the deletion of the object for the RecoveryConfig, replacing the previous one of the same object
+func (q *pendingDeletionQueue) add(obj *unstructured.Unstructured, recoveryConfig *kuberecoveryv1alpha1.RecoveryConfig) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.items[getPendingDeletionKey(recoveryConfig.Name, obj.GetUID())] = &pendingDeletion{
+		obj:            obj,
+		recoveryConfig: recoveryConfig,
+		admittedAt:     time.Now(),
+	}
+}
+
+// take removes the deletion of the object for the RecoveryConfig from the queue and returns it, if it is queued
+func (q *pendingDeletionQueue) take(recoveryConfigName string, uid types.UID) *pendingDeletion {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	key := getPendingDeletionKey(recoveryConfigName, uid)
+	pending := q.items[key]
+	delete(q.items, key)
+	return pending
+}
+
+// list returns the deletions in the queue admitted before the time
+func (q *pendingDeletionQueue) list(admittedBefore time.Time) (pendings []*pendingDeletion) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, pending := range q.items {
+		if pending.admittedAt.Before(admittedBefore) {
+			pendings = append(pendings, pending)
+		}
+	}
+	return pendings
+}
+
+// getPendingDeletionKey returns the key of the deletion of the object with the UID for the RecoveryConfig
+func getPendingDeletionKey(recoveryConfigName string, uid types.UID) string {
+	return fmt.Sprintf("%s/%s", recoveryConfigName, uid)
+}
+
+// SetupWebhookWithManager registers the deletion capture and the RecoveryConfig validation webhooks in the webhook
+// server of the Manager
+func (r *RecoveryConfigReconciler) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	r.deletionCaptureEnabled = true
+	r.pendingDeletions = newPendingDeletionQueue()
+
+	deletionCapture := &deletionCaptureWebhook{reconciler: r}
+	mgr.GetWebhookServer().Register(deletionCaptureWebhookPath, &webhook.Admission{Handler: deletionCapture})
+	mgr.GetWebhookServer().Register(recoveryConfigValidationWebhookPath, admission.WithCustomValidator(mgr.GetScheme(),
+		&kuberecoveryv1alpha1.RecoveryConfig{}, &recoveryConfigValidator{reconciler: r}))
+
+	// The deletions missed by the informers are captured in every replica serving the webhook
+	return mgr.Add(deletionCapture)
+}
+
+// Handle queues the object being deleted for every RecoveryConfig watching it. Nothing is read from the API server,
+// nor stored, so the webhook answers within its timeout
+func (w *deletionCaptureWebhook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	logger := log.FromContext(ctx)
+
+	// Side effects are not allowed on dry-run requests
+	if req.Operation != admissionv1.Delete || req.SubResource != "" || (req.DryRun != nil && *req.DryRun) {
+		return admission.Allowed("")
+	}
+
+	// Get the RecoveryConfigs watching the resource, if any
+	gvr := schema.GroupVersionResource{
+		Group:    req.Resource.Group,
+		Version:  req.Resource.Version,
+		Resource: req.Resource.Resource,
+	}
+	watchers := w.reconciler.getResourceWatchers(ctx, gvr, req.Namespace)
+	if len(watchers) == 0 || len(req.OldObject.Raw) == 0 {
+		return admission.Allowed("")
+	}
+
+	// Get the object being deleted as unstructured object
+	obj := &unstructured.Unstructured{}
+	err := obj.UnmarshalJSON(req.OldObject.Raw)
+	if err != nil {
+		logger.Info(fmt.Sprintf(decodeAdmissionObjectError, req.UID, err))
+		return admission.Allowed("")
+	}
+
+	// Informers with a label selector only watch the resources matching it
+	recoveryConfigs := getRecoveryConfigsWatching(watchers, labels.Set(obj.GetLabels()))
+
+	for _, recoveryConfig := range recoveryConfigs {
+		w.reconciler.pendingDeletions.add(obj.DeepCopy(), recoveryConfig)
+	}
+
+	return admission.Allowed("")
+}
+
+// Start captures the pending deletions missed by the informers until the context is done
+func (w *deletionCaptureWebhook) Start(ctx context.Context) error {
+	ticker := time.NewTicker(pendingDeletionSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			w.capturePendingDeletions(ctx)
+		}
+	}
+}
+
+// NeedLeaderElection returns false, as the webhook is served by every replica
+func (w *deletionCaptureWebhook) NeedLeaderElection() bool {
+	return false
+}
+
+// capturePendingDeletions captures the deletions admitted long enough ago that the informers should have seen them.
+// The ones whose resource is gone are captured now, and the ones whose resource still exists, as its deletion failed
+// or it is waiting for its finalizers, are kept until they expire
+func (w *deletionCaptureWebhook) capturePendingDeletions(ctx context.Context) {
+	logger := log.FromContext(ctx)
+
+	for _, pending := range w.reconciler.pendingDeletions.list(time.Now().Add(-pendingDeletionGracePeriod)) {
+		obj := pending.obj
+
+		deleted, err := isResourceDeleted(ctx, obj)
+		if err != nil {
+			logger.Info(fmt.Sprintf(getPendingDeletionError, obj.GetAPIVersion(), obj.GetKind(), obj.GetNamespace(),
+				obj.GetName(), err))
+		}
+
+		switch {
+		case err == nil && deleted:
+			w.reconciler.pendingDeletions.take(pending.recoveryConfig.Name, obj.GetUID())
+			logger.Info(fmt.Sprintf(pendingDeletionCapturedMessage, obj.GetAPIVersion(), obj.GetKind(),
+				obj.GetNamespace(), obj.GetName()))
+			w.reconciler.captureDeletedResource(ctx, obj, pending.recoveryConfig, captureOptions{})
+
+		case time.Since(pending.admittedAt) > pendingDeletionExpiration:
+			w.reconciler.pendingDeletions.take(pending.recoveryConfig.Name, obj.GetUID())
+			logger.Info(fmt.Sprintf(pendingDeletionExpiredMessage, obj.GetAPIVersion(), obj.GetKind(),
+				obj.GetNamespace(), obj.GetName(), pendingDeletionExpiration))
+		}
+	}
+}
+
+// isResourceDeleted returns true if the resource is not found in the cluster, or it was created again with another UID
+func isResourceDeleted(ctx context.Context, obj *unstructured.Unstructured) (bool, error) {
+	dynamicClient, err := getResourceClient(obj)
+	if err != nil {
+		return false, err
+	}
+
+	liveResource, err := dynamicClient.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return liveResource.GetUID() != obj.GetUID(), nil
+}
+
+// getResourceWatchers returns the watchers of the RecoveryConfigs with an informer for the resource in the namespace
+func (r *RecoveryConfigReconciler) getResourceWatchers(ctx context.Context, gvr schema.GroupVersionResource,
+	namespace string) (watchers []*pools.ResourceWatcher) {
+
+	apiVersion := gvr.GroupVersion().String()
+
+	for _, watcher := range r.ResourceWatcherPool.GetAll() {
+		if watcher.APIVersion != apiVersion || watcher.Resource != gvr.Resource {
+			continue
+		}
+		if watcher.Namespace != "" && watcher.Namespace != namespace {
+			continue
+		}
+		if !r.isNamespaceSelected(ctx, watcher.NamespaceSelector, namespace) {
+			continue
+		}
+
+		watchers = append(watchers, watcher)
+	}
+
+	return watchers
+}
+
+// getRecoveryConfigsWatching returns the RecoveryConfigs of the watchers whose label selector matches the labels
+func getRecoveryConfigsWatching(watchers []*pools.ResourceWatcher,
+	objLabels labels.Set) (recoveryConfigs []*kuberecoveryv1alpha1.RecoveryConfig) {
+
+	found := make(map[string]bool)
+
+	for _, watcher := range watchers {
+		if watcher.LabelSelector != nil && !watcher.LabelSelector.Matches(objLabels) {
+			continue
+		}
+		if found[watcher.RecoveryConfig.Name] {
+			continue
+		}
+
+		found[watcher.RecoveryConfig.Name] = true
+		recoveryConfigs = append(recoveryConfigs, watcher.RecoveryConfig)
+	}
+
+	return recoveryConfigs
+}
+
+// syncDeletionCaptureRules narrows the rules of the deletion capture webhook to the resources included by the
+// RecoveryConfigs, so the deletions of every other resource in the cluster never go through the operator.
+// The webhook is looked up by name in the ValidatingWebhookConfiguration of the operator
+func (r *RecoveryConfigReconciler) syncDeletionCaptureRules(ctx context.Context) error {
+	if !r.deletionCaptureEnabled {
+		return nil
+	}
+
+	recoveryConfigs := &kuberecoveryv1alpha1.RecoveryConfigList{}
+	err := r.List(ctx, recoveryConfigs)
+	if err != nil {
+		return fmt.Errorf(listRecoveryConfigsError, err)
+	}
+	rules := getDeletionCaptureRules(recoveryConfigs.Items)
+
+	webhookConfiguration, err := globals.Application.KubeRawCoreClient.AdmissionregistrationV1().
+		ValidatingWebhookConfigurations().Get(ctx, r.ValidatingWebhookConfiguration, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf(syncDeletionCaptureRulesError, err)
+	}
+
+	index := slices.IndexFunc(webhookConfiguration.Webhooks,
+		func(candidate admissionregistrationv1.ValidatingWebhook) bool {
+			return candidate.Name == deletionCaptureWebhookName
+		})
+	if index < 0 || reflect.DeepEqual(webhookConfiguration.Webhooks[index].Rules, rules) {
+		return nil
+	}
+
+	webhookConfiguration.Webhooks[index].Rules = rules
+	_, err = globals.Application.KubeRawCoreClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().
+		Update(ctx, webhookConfiguration, metav1.UpdateOptions{})
+	if err != nil {
+		return fmt.Errorf(syncDeletionCaptureRulesError, err)
+	}
+	log.FromContext(ctx).Info(fmt.Sprintf(deletionCaptureRulesSyncedMessage, webhookConfiguration.Name, len(rules)))
+
+	return nil
+}
+
+// getDeletionCaptureRules returns a DELETE rule for every apiVersion included by the RecoveryConfigs not being deleted,
+// with the resources included in it. The rules are sorted, so they only change when the RecoveryConfigs do
+func getDeletionCaptureRules(
+	recoveryConfigs []kuberecoveryv1alpha1.RecoveryConfig) []admissionregistrationv1.RuleWithOperations {
+
+	resources := make(map[string][]string)
+	for _, recoveryConfig := range recoveryConfigs {
+		if !recoveryConfig.DeletionTimestamp.IsZero() {
+			continue
+		}
+		for _, included := range recoveryConfig.Spec.ResourcesIncluded {
+			for _, resource := range included.Resources {
+				if !slices.Contains(resources[included.APIVersion], resource) {
+					resources[included.APIVersion] = append(resources[included.APIVersion], resource)
+				}
+			}
+		}
+	}
+
+	apiVersions := make([]string, 0, len(resources))
+	for apiVersion := range resources {
+		apiVersions = append(apiVersions, apiVersion)
+	}
+	sort.Strings(apiVersions)
+
+	rules := []admissionregistrationv1.RuleWithOperations{}
+	for _, apiVersion := range apiVersions {
+		groupVersion, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			continue
+		}
+		sort.Strings(resources[apiVersion])
+
+		scope := admissionregistrationv1.AllScopes
+		rules = append(rules, admissionregistrationv1.RuleWithOperations{
+			Operations: []admissionregistrationv1.OperationType{admissionregistrationv1.Delete},
+			Rule: admissionregistrationv1.Rule{
+				APIGroups:   []string{groupVersion.Group},
+				APIVersions: []string{groupVersion.Version},
+				Resources:   resources[apiVersion],
+				Scope:       &scope,
+			},
+		})
+	}
+
+	return rules
+}
@@ -0,0 +1,175 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/pools"
+)
+
+func TestCapturePendingDeletions(t *testing.T) {
+	newConfigMap := func(uid types.UID) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{}
+		obj.SetAPIVersion("v1")
+		obj.SetKind("ConfigMap")
+		obj.SetNamespace("default")
+		obj.SetName("settings")
+		obj.SetUID(uid)
+		return obj
+	}
+
+	tests := []struct {
+		name       string
+		live       *unstructured.Unstructured
+		admittedAt time.Duration
+		captured   bool
+		queued     bool
+	}{
+		{
+			name:       "deletion within the grace period is kept for the informers",
+			admittedAt: pendingDeletionGracePeriod / 2,
+			queued:     true,
+		},
+		{
+			name:       "resource gone is captured",
+			admittedAt: 2 * pendingDeletionGracePeriod,
+			captured:   true,
+		},
+		{
+			name:       "resource created again with another UID is captured",
+			live:       newConfigMap("uid-new"),
+			admittedAt: 2 * pendingDeletionGracePeriod,
+			captured:   true,
+		},
+		{
+			name:       "resource still existing is kept",
+			live:       newConfigMap("uid-settings"),
+			admittedAt: 2 * pendingDeletionGracePeriod,
+			queued:     true,
+		},
+		{
+			name:       "resource still existing after the expiration is forgotten",
+			live:       newConfigMap("uid-settings"),
+			admittedAt: 2 * pendingDeletionExpiration,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			objects := []runtime.Object{}
+			if test.live != nil {
+				objects = append(objects, test.live)
+			}
+			setupFakeClients(t, objects...)
+
+			scheme := runtime.NewScheme()
+			_ = corev1.AddToScheme(scheme)
+			_ = kuberecoveryv1alpha1.AddToScheme(scheme)
+			reconciler := &RecoveryConfigReconciler{
+				Client:           fake.NewClientBuilder().WithScheme(scheme).Build(),
+				Scheme:           scheme,
+				pendingDeletions: newPendingDeletionQueue(),
+			}
+			recoveryConfig := &kuberecoveryv1alpha1.RecoveryConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "settings"},
+				Spec: kuberecoveryv1alpha1.RecoveryConfigSpec{
+					Retention: kuberecoveryv1alpha1.RetentionT{Period: "24h"},
+				},
+			}
+
+			reconciler.pendingDeletions.add(newConfigMap("uid-settings"), recoveryConfig)
+			for _, pending := range reconciler.pendingDeletions.items {
+				pending.admittedAt = time.Now().Add(-test.admittedAt)
+			}
+
+			webhook := &deletionCaptureWebhook{reconciler: reconciler}
+			webhook.capturePendingDeletions(ctx)
+
+			captured, err := isResourceCaptured(ctx, "uid-settings", recoveryConfig.Name)
+			if err != nil {
+				t.Fatalf("isResourceCaptured() error = %v", err)
+			}
+			if captured != test.captured {
+				t.Errorf("captured = %t, expected %t", captured, test.captured)
+			}
+
+			queued := reconciler.pendingDeletions.take(recoveryConfig.Name, "uid-settings") != nil
+			if queued != test.queued {
+				t.Errorf("queued = %t, expected %t", queued, test.queued)
+			}
+		})
+	}
+}
+
+func TestDeletionCaptureWebhookQueues(t *testing.T) {
+	ctx := context.Background()
+	dynamicClient := setupFakeClients(t)
+
+	recoveryConfig := &kuberecoveryv1alpha1.RecoveryConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings"},
+	}
+	reconciler := &RecoveryConfigReconciler{
+		ResourceWatcherPool: &pools.ResourceWatcherStore{
+			Store: map[string]*pools.ResourceWatcher{
+				"settings": {RecoveryConfig: recoveryConfig, APIVersion: "v1", Resource: "configmaps"},
+			},
+		},
+		pendingDeletions: newPendingDeletionQueue(),
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace("default")
+	obj.SetName("settings")
+	obj.SetUID("uid-settings")
+	raw, _ := json.Marshal(obj.Object)
+
+	response := (&deletionCaptureWebhook{reconciler: reconciler}).Handle(ctx, admission.Request{
+		AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Delete,
+			Resource:  metav1.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+			Namespace: "default",
+			OldObject: runtime.RawExtension{Raw: raw},
+		},
+	})
+	if !response.Allowed {
+		t.Errorf("Handle() denied the deletion: %v", response.Result)
+	}
+	if len(dynamicClient.Actions()) != 0 {
+		t.Errorf("Handle() called the API server: %v", dynamicClient.Actions())
+	}
+	if reconciler.pendingDeletions.take(recoveryConfig.Name, "uid-settings") == nil {
+		t.Errorf("Handle() did not queue the deletion")
+	}
+}
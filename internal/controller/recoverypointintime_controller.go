@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/storage"
+)
+
+// RecoveryPointInTimeReconciler reconciles a RecoveryPointInTime object
+type RecoveryPointInTimeReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	StorageBackend storage.Backend
+}
+
+// +kubebuilder:rbac:groups=kuberecovery.freepik.com,resources=recoverypointintimes,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kuberecovery.freepik.com,resources=recoverypointintimes/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kuberecovery.freepik.com,resources=recoverypointintimes/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.19.1/pkg/reconcile
+func (r *RecoveryPointInTimeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	logger := log.FromContext(ctx)
+
+	// 1. Get the content of the RecoveryPointInTime
+	pointInTime := &kuberecoveryv1alpha1.RecoveryPointInTime{}
+	err = r.Get(ctx, req.NamespacedName, pointInTime)
+
+	// 2. Check existence on the cluster
+	if err != nil {
+
+		// 2.1 It does NOT exist: nothing to do
+		if err = client.IgnoreNotFound(err); err == nil {
+			logger.Info(fmt.Sprintf(resourceNotFoundError, recoveryPointInTimeType, req.NamespacedName))
+			return result, err
+		}
+
+		// 2.2 Failed to get the resource, requeue the request
+		logger.Info(fmt.Sprintf(resourceSyncTimeRetrievalError, recoveryPointInTimeType, req.NamespacedName, err.Error()))
+		return result, err
+	}
+
+	// 3. The reconstruction is done just once, the RecoveryPointInTime is kept as a record of it
+	if pointInTime.Status.CompletionTime != nil {
+		return result, nil
+	}
+
+	// 4. Update the status before leaving
+	defer func() {
+		statusErr := r.Status().Update(ctx, pointInTime)
+		if statusErr != nil {
+			logger.Info(fmt.Sprintf(resourceConditionUpdateError, recoveryPointInTimeType, req.NamespacedName, statusErr.Error()))
+		}
+	}()
+
+	// 5. Reconstruct the namespace
+	err = r.Sync(ctx, pointInTime)
+	if err != nil {
+		r.UpdateConditionKubernetesApiCallFailure(pointInTime)
+		logger.Info(fmt.Sprintf(syncTargetError, recoveryPointInTimeType, req.NamespacedName, err.Error()))
+		return result, err
+	}
+
+	// 6. Success, update the status. The resources that could not be restored are reported in the condition,
+	// without requeueing the request, as they are not restored again
+	if failed := countFailedItems(pointInTime.Status.Items); failed > 0 {
+		r.UpdateConditionPartialFailure(pointInTime, failed)
+		return result, nil
+	}
+	r.UpdateConditionSuccess(pointInTime)
+
+	return result, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RecoveryPointInTimeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kuberecoveryv1alpha1.RecoveryPointInTime{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Named("recoverypointintime").
+		Complete(r)
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+// Sync reconstructs the namespace of the RecoveryPointInTime as it was at the requested time.
+// Every resource deleted or updated after that time was captured in a RecoveryResource holding the state it had
+// before the change, so the earliest capture after the time is the state of the resource at that time
+func (r *RecoveryPointInTimeReconciler) Sync(ctx context.Context,
+	resource *kuberecoveryv1alpha1.RecoveryPointInTime) (err error) {
+
+	logger := log.FromContext(ctx)
+
+	if resource.Status.StartTime == nil {
+		now := metav1.Now()
+		resource.Status.StartTime = &now
+	}
+
+	// Get the state of every resource of the namespace at the point in time
+	candidates, err := r.getPointInTimeCandidates(ctx, resource)
+	if err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf(pointInTimeComputedMessage, resource.Spec.Namespace,
+		resource.Spec.Time.UTC().Format(time.RFC3339), len(candidates)))
+
+	// Restore the resources missing or changed since then. The result of every one is recorded in the status as soon
+	// as it is restored, so an interrupted reconstruction carries on from the first resource not restored yet
+	uidMapping := maps.Clone(resource.Status.UIDMapping)
+	if uidMapping == nil {
+		uidMapping = make(map[string]string)
+	}
+	recordStatus := func() error {
+		resource.Status.UIDMapping = uidMapping
+		err := r.Status().Update(ctx, resource)
+		if err != nil {
+			return fmt.Errorf(recordRestoreProgressError, resource.Name, err)
+		}
+		return nil
+	}
+
+	err = restoreCandidates(candidates, &resource.Status.Items, recordStatus,
+		func(candidate recoveryCandidate) kuberecoveryv1alpha1.RestoredItemT {
+			item := newRestoredItem(candidate.recoveryResource, candidate.identity)
+
+			// Resources are replaced when they exist, and kept untouched when they did not change
+			lastRestore, _ := restoreRecoveryResource(ctx, r.StorageBackend, candidate.recoveryResource, restoreRequest{
+				conflictStrategy: conflictStrategyReplace,
+				skipUnchanged:    true,
+				uidMapping:       uidMapping,
+			})
+			if lastRestore.Result != restoreResultUnchanged {
+				updateLastRestore(ctx, r.Client, candidate.recoveryResource, lastRestore)
+			}
+			recordUIDMapping(uidMapping, candidate.recoveryResource, lastRestore)
+
+			item.ConflictStrategy = lastRestore.ConflictStrategy
+			item.Result = lastRestore.Result
+			item.Message = lastRestore.Message
+			item.Steps = lastRestore.Steps
+			return item
+		})
+	if err != nil {
+		return err
+	}
+
+	// The reconstruction is completed also when some resources failed, as they are reported in their items
+	now := metav1.Now()
+	resource.Status.UIDMapping = uidMapping
+	resource.Status.CompletionTime = &now
+
+	return nil
+}
+
+// getPointInTimeCandidates returns, for every resource of the namespace changed after the point in time,
+// the RecoveryResource holding the state it had at that time
+func (r *RecoveryPointInTimeReconciler) getPointInTimeCandidates(ctx context.Context,
+	resource *kuberecoveryv1alpha1.RecoveryPointInTime) (candidates []recoveryCandidate, err error) {
+
+	logger := log.FromContext(ctx)
+
+	recoveryResources := &kuberecoveryv1alpha1.RecoveryResourceList{}
+	err = r.List(ctx, recoveryResources)
+	if err != nil {
+		return nil, fmt.Errorf(listRecoveryResourcesError, err)
+	}
+
+	pointInTime := resource.Spec.Time.UTC()
+	selected := make(map[string]recoveryCandidate)
+	for i := range recoveryResources.Items {
+		recoveryResource := &recoveryResources.Items[i]
+		labels := recoveryResource.GetLabels()
+
+		if len(resource.Spec.RecoveryConfigs) > 0 &&
+			!slices.Contains(resource.Spec.RecoveryConfigs, getCaptureRecoveryConfig(recoveryResource)) {
+			continue
+		}
+
+		// Changes done before the point in time do not hold its state
+		savedAt, err := getSavedAt(recoveryResource)
+		if err != nil {
+			logger.Info(fmt.Sprintf(parseRecoveryResourceError, recoveryResource.Name, err))
+			continue
+		}
+		if !savedAt.After(pointInTime) {
+			continue
+		}
+
+		identity, err := getRecoveryResourceIdentity(recoveryResource)
+		if err != nil {
+			logger.Info(fmt.Sprintf(parseRecoveryResourceError, recoveryResource.Name, err))
+			continue
+		}
+		if identity.GetNamespace() != resource.Spec.Namespace {
+			continue
+		}
+
+		// Resources created after the point in time did not exist then
+		creationTimestamp := recoveryResource.GetAnnotations()[recoveryResourceSourceCreationAnnotation]
+		if createdAt, err := time.Parse(time.RFC3339, creationTimestamp); err == nil && createdAt.After(pointInTime) {
+			continue
+		}
+
+		// Captures of the same resource are grouped by its UID. The RecoveryResources saved before the UID
+		// was recorded are grouped by kind and name
+		key := labels[recoveryResourceSourceUIDLabel]
+		if key == "" {
+			key = fmt.Sprintf("%s/%s/%s", identity.GetAPIVersion(), identity.GetKind(), identity.GetName())
+		}
+
+		candidate := recoveryCandidate{
+			recoveryResource: recoveryResource,
+			identity:         identity,
+			savedAt:          savedAt,
+		}
+		if current, exists := selected[key]; !exists || isEarlierCapture(candidate, current) {
+			selected[key] = candidate
+		}
+	}
+
+	for _, candidate := range selected {
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].recoveryResource.Name < candidates[j].recoveryResource.Name
+	})
+	sortByRestoreOrder(candidates, nil)
+	sortByOwnership(candidates)
+
+	return candidates, nil
+}
+
+// isEarlierCapture returns true if the capture a was done before the capture b. The savedAt label has a precision
+// of seconds, so on the same second the update revisions go before the deletion and the lower revisions first
+func isEarlierCapture(a, b recoveryCandidate) bool {
+	if !a.savedAt.Equal(b.savedAt) {
+		return a.savedAt.Before(b.savedAt)
+	}
+
+	aIsUpdate := a.recoveryResource.GetLabels()[recoveryResourceCaptureReasonLabel] == captureReasonUpdate
+	bIsUpdate := b.recoveryResource.GetLabels()[recoveryResourceCaptureReasonLabel] == captureReasonUpdate
+	if aIsUpdate != bIsUpdate {
+		return aIsUpdate
+	}
+
+	return getRevision(a.recoveryResource) < getRevision(b.recoveryResource)
+}
@@ -24,23 +24,34 @@ import (
 	"time"
 
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/storage"
 )
 
 // RecoveryResourceReconciler reconciles a RecoveryResource object
 type RecoveryResourceReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
+	Scheme         *runtime.Scheme
+	StorageBackend storage.Backend
+	Expiration     ExpirationOptions
+	Recorder       record.EventRecorder
+
+	expirations *expirationScheduler
+
+	// retentionAuditWebhookEnabled is true when the retention audit webhook is served, so the requester annotations
+	// can be trusted
+	retentionAuditWebhookEnabled bool
 }
 
 // +kubebuilder:rbac:groups=kuberecovery.freepik.com,resources=recoveryresources,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=kuberecovery.freepik.com,resources=recoveryresources/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=kuberecovery.freepik.com,resources=recoveryresources/finalizers,verbs=update
-// +kubebuilder:rbac:groups=*,resources=*,verbs=get;list;watch;create
+// +kubebuilder:rbac:groups=*,resources=*,verbs=get;list;watch;create;update;patch;delete
 
 // Reconcile is part of the main kubernetes reconciliation loop which aims to
 // move the current state of the cluster closer to the desired state.
@@ -60,6 +71,7 @@ func (r *RecoveryResourceReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		// 2.1 It does NOT exist: manage removal
 		if err = client.IgnoreNotFound(err); err == nil {
 			logger.Info(fmt.Sprintf(resourceNotFoundError, recoveryResourceType, req.NamespacedName))
+			r.expirations.forget(req.Name)
 			return result, err
 		}
 
@@ -68,9 +80,15 @@ func (r *RecoveryResourceReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return result, err
 	}
 
-	// 3. Check if the RecoveryResource resource is marked to be deleted: indicated by the deletion timestamp being set
-	if !kubeRecoveryResource.DeletionTimestamp.IsZero() {
+	// 3. Check if the RecoveryResource resource is marked to be deleted: indicated by the deletion timestamp being set.
+	// Resources still protected by the extra finalizer are synced until it is removed on expiration or purge
+	if !kubeRecoveryResource.DeletionTimestamp.IsZero() &&
+		!controllerutil.ContainsFinalizer(kubeRecoveryResource, recoveryResourceExtraFinalizer) {
 		if controllerutil.ContainsFinalizer(kubeRecoveryResource, resourceFinalizer) {
+			// This is the only place deleting the payload from the storage backend, once the resource
+			// is going away for sure
+			r.deletePayload(ctx, kubeRecoveryResource)
+
 			// Remove the finalizers on Patch CR
 			controllerutil.RemoveFinalizer(kubeRecoveryResource, resourceFinalizer)
 			err = r.Update(ctx, kubeRecoveryResource)
@@ -100,23 +118,29 @@ func (r *RecoveryResourceReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		}
 	}()
 
-	// 6. Schedule periodical request
-	RequeueTime, err := time.ParseDuration(defaultSyncInterval)
+	// 6. Check if the resource can be deleted
+	err = r.Sync(ctx, kubeRecoveryResource)
 	if err != nil {
-		logger.Info(fmt.Sprintf(resourceSyncTimeRetrievalError, recoveryResourceType, req.NamespacedName, err.Error()))
+		r.UpdateConditionKubernetesApiCallFailure(kubeRecoveryResource)
+		logger.Info(fmt.Sprintf(syncTargetError, recoveryResourceType, req.NamespacedName, err.Error()))
 		return result, err
 	}
-	result = ctrl.Result{
-		RequeueAfter: RequeueTime,
-	}
 
-	// 7. Check if the resource can be deleted
-	err = r.Sync(ctx, kubeRecoveryResource)
+	// 7. Schedule the next request when the resource expires, or sooner while the restored resource is being verified
+	result.RequeueAfter, err = r.expirations.getRequeueAfter(kubeRecoveryResource)
 	if err != nil {
-		r.UpdateConditionKubernetesApiCallFailure(kubeRecoveryResource)
-		logger.Info(fmt.Sprintf(syncTargetError, recoveryResourceType, req.NamespacedName, err.Error()))
+		logger.Info(fmt.Sprintf(resourceSyncTimeRetrievalError, recoveryResourceType, req.NamespacedName, err.Error()))
 		return result, err
 	}
+	if isRestoreVerificationPending(kubeRecoveryResource) {
+		verificationInterval, err := time.ParseDuration(restoreVerificationInterval)
+		if err != nil {
+			return result, err
+		}
+		if result.RequeueAfter == 0 || verificationInterval < result.RequeueAfter {
+			result.RequeueAfter = verificationInterval
+		}
+	}
 
 	// 8. Success, update the status
 	r.UpdateConditionSuccess(kubeRecoveryResource)
@@ -124,11 +148,41 @@ func (r *RecoveryResourceReconciler) Reconcile(ctx context.Context, req ctrl.Req
 	return result, err
 }
 
+// deletePayload removes the payload of the RecoveryResource from the storage backend, if it was stored there
+func (r *RecoveryResourceReconciler) deletePayload(ctx context.Context,
+	resource *kuberecoveryv1alpha1.RecoveryResource) {
+
+	logger := log.FromContext(ctx)
+
+	payloadRef := resource.GetAnnotations()[recoveryResourcePayloadRefAnnotation]
+	if payloadRef == "" {
+		return
+	}
+
+	if r.StorageBackend == nil {
+		logger.Info(fmt.Sprintf(storageBackendNotConfiguredError, payloadRef))
+		return
+	}
+
+	err := r.StorageBackend.Delete(ctx, payloadRef)
+	if err != nil {
+		logger.Info(fmt.Sprintf(deletePayloadError, payloadRef, err))
+	}
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *RecoveryResourceReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	r.expirations = newExpirationScheduler(r.Expiration)
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&kuberecoveryv1alpha1.RecoveryResource{}).
-		WithEventFilter(predicate.LabelChangedPredicate{}).
+		WithEventFilter(predicate.Or[client.Object](
+			predicate.LabelChangedPredicate{},
+			// Deleted RecoveryResources are finalized right away, deleting their payloads
+			predicate.NewPredicateFuncs(func(object client.Object) bool {
+				return !object.GetDeletionTimestamp().IsZero()
+			}),
+		)).
 		Named("recoveryresource").
 		Complete(r)
 }
@@ -0,0 +1,137 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/storage"
+)
+
+func TestRecoveryResourcePayloadDeletion(t *testing.T) {
+	expired := time.Now().UTC().Add(-time.Hour).Format(timeParseFormat)
+	retained := time.Now().UTC().Add(time.Hour).Format(timeParseFormat)
+	deletionTimestamp := metav1.Now()
+
+	tests := []struct {
+		name              string
+		retainUntil       string
+		finalizers        []string
+		deletionTimestamp *metav1.Time
+
+		// Payload and RecoveryResource expected after every reconcile
+		expectedPayloads []bool
+		expectedExists   []bool
+	}{
+		{
+			name:             "expired RecoveryResource deleted before its payload",
+			retainUntil:      expired,
+			finalizers:       []string{resourceFinalizer, recoveryResourceExtraFinalizer},
+			expectedPayloads: []bool{true, false},
+			expectedExists:   []bool{true, false},
+		},
+		{
+			name:              "RecoveryResource deleted by hand while it is protected",
+			retainUntil:       retained,
+			finalizers:        []string{resourceFinalizer, recoveryResourceExtraFinalizer},
+			deletionTimestamp: &deletionTimestamp,
+			expectedPayloads:  []bool{true, true},
+			expectedExists:    []bool{true, true},
+		},
+		{
+			name:              "RecoveryResource deleted by hand once it is not protected",
+			retainUntil:       retained,
+			finalizers:        []string{resourceFinalizer},
+			deletionTimestamp: &deletionTimestamp,
+			expectedPayloads:  []bool{false},
+			expectedExists:    []bool{false},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+
+			backend, err := storage.NewFilesystemBackend(t.TempDir())
+			if err != nil {
+				t.Fatalf("unexpected error creating the backend: %v", err)
+			}
+			payloadRef, err := backend.Put(ctx, "recoveryconfig-sample-configmap-settings.json", []byte(`{}`))
+			if err != nil {
+				t.Fatalf("unexpected error putting the payload: %v", err)
+			}
+
+			recoveryResource := &kuberecoveryv1alpha1.RecoveryResource{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "recoveryconfig-sample-configmap-settings",
+					Labels:            map[string]string{recoveryResourceRetainUntilLabel: test.retainUntil},
+					Annotations:       map[string]string{recoveryResourcePayloadRefAnnotation: payloadRef},
+					Finalizers:        test.finalizers,
+					DeletionTimestamp: test.deletionTimestamp,
+				},
+				Spec: runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"ConfigMap"}`)},
+			}
+
+			scheme := runtime.NewScheme()
+			if err = kuberecoveryv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("unexpected error building the scheme: %v", err)
+			}
+			reconciler := &RecoveryResourceReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(recoveryResource).
+					WithStatusSubresource(recoveryResource).Build(),
+				Scheme:         scheme,
+				StorageBackend: backend,
+				Recorder:       record.NewFakeRecorder(10),
+				expirations:    newExpirationScheduler(ExpirationOptions{}),
+			}
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: recoveryResource.Name}}
+			for i := range test.expectedPayloads {
+				_, _ = reconciler.Reconcile(ctx, request)
+
+				_, err = backend.Get(ctx, payloadRef)
+				if payloadExists := err == nil; payloadExists != test.expectedPayloads[i] {
+					t.Errorf("reconcile %d: expected the payload to exist %t, got %t", i, test.expectedPayloads[i],
+						payloadExists)
+				}
+
+				current := &kuberecoveryv1alpha1.RecoveryResource{}
+				err = reconciler.Get(ctx, request.NamespacedName, current)
+				if exists := !apierrors.IsNotFound(err); exists != test.expectedExists[i] {
+					t.Errorf("reconcile %d: expected the RecoveryResource to exist %t, got %t", i,
+						test.expectedExists[i], exists)
+				}
+				if exists := err == nil; exists && controllerutil.ContainsFinalizer(current, resourceFinalizer) !=
+					test.expectedPayloads[i] {
+					t.Errorf("reconcile %d: expected the finalizer to be kept while the payload exists", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+// minExpirationRequeue is the minimum time until a RecoveryResource is reconciled again to check its expiration
+const minExpirationRequeue = time.Second
+
+// ExpirationOptions configures how the expired RecoveryResources are deleted
+type ExpirationOptions struct {
+	// Jitter is the maximum random delay added to the expiration of every RecoveryResource,
+	// so the ones expiring at the same time are not deleted together
+	Jitter time.Duration
+
+	// DeletionRate is the maximum number of expired RecoveryResources deleted per second, with bursts of
+	// DeletionBurst deletions. A rate of 0 disables the limit
+	DeletionRate  float64
+	DeletionBurst int
+}
+
+// expirationScheduler spreads the deletion of the expired RecoveryResources over time. Every expired
+// RecoveryResource reserves a slot of the deletion rate, and it is deleted when its slot is reached
+type expirationScheduler struct {
+	options ExpirationOptions
+	limiter *rate.Limiter
+
+	mutex sync.Mutex
+	slots map[string]*rate.Reservation
+}
+
+// newExpirationScheduler returns a scheduler deleting the expired RecoveryResources with the options
+func newExpirationScheduler(options ExpirationOptions) *expirationScheduler {
+	limit := rate.Inf
+	if options.DeletionRate > 0 {
+		limit = rate.Limit(options.DeletionRate)
+	}
+	burst := options.DeletionBurst
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &expirationScheduler{
+		options: options,
+		limiter: rate.NewLimiter(limit, burst),
+		slots:   map[string]*rate.Reservation{},
+	}
+}
+
+// reserve returns true if the expired RecoveryResource can be deleted now. Otherwise, a slot is reserved for it
+// the first time, and it can be deleted once the slot is reached
+func (s *expirationScheduler) reserve(name string) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if slot, reserved := s.slots[name]; reserved {
+		if slot.Delay() > 0 {
+			return false
+		}
+		delete(s.slots, name)
+		return true
+	}
+
+	slot := s.limiter.Reserve()
+	if slot.Delay() == 0 {
+		return true
+	}
+	s.slots[name] = slot
+	return false
+}
+
+// forget removes the slot reserved for the RecoveryResource, when it was deleted by someone else or held.
+// The slot is given back to the limiter, so the RecoveryResources waiting after it are not delayed by it
+func (s *expirationScheduler) forget(name string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if slot, reserved := s.slots[name]; reserved {
+		slot.Cancel()
+		delete(s.slots, name)
+	}
+}
+
+// getRequeueAfter returns the time until the RecoveryResource has to be reconciled again: its deletion slot, when it is
+// expired and waiting for it, or its expiration with a random jitter otherwise.
+// Held resources are not requeued, as they are reconciled again when they are released
+func (s *expirationScheduler) getRequeueAfter(resource *kuberecoveryv1alpha1.RecoveryResource) (time.Duration, error) {
+	if isHeld(resource) {
+		s.forget(resource.Name)
+		return 0, nil
+	}
+
+	s.mutex.Lock()
+	slot, reserved := s.slots[resource.Name]
+	s.mutex.Unlock()
+	if reserved {
+		return max(slot.Delay(), minExpirationRequeue), nil
+	}
+
+	validUntil, err := time.Parse(timeParseFormat, resource.GetLabels()[recoveryResourceRetainUntilLabel])
+	if err != nil {
+		return 0, fmt.Errorf(timeParseError, err)
+	}
+
+	requeueAfter := time.Until(validUntil)
+	if s.options.Jitter > 0 {
+		requeueAfter += rand.N(s.options.Jitter)
+	}
+	return max(requeueAfter, minExpirationRequeue), nil
+}
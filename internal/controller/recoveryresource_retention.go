@@ -0,0 +1,199 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+// isHeld returns true if the RecoveryResource is held, so it is never expired nor evicted
+func isHeld(resource metav1.Object) bool {
+	return resource.GetLabels()[recoveryResourceHoldLabel] == recoveryResourceHoldLabelValue
+}
+
+// syncRetentionRequests applies the holds and the extensions of the retention requested in the labels of the
+// RecoveryResource. It returns the actions done, to be recorded in its status as audit trail
+func (r *RecoveryResourceReconciler) syncRetentionRequests(ctx context.Context,
+	resource *kuberecoveryv1alpha1.RecoveryResource) ([]kuberecoveryv1alpha1.RetentionEventT, error) {
+
+	logger := log.FromContext(ctx)
+	now := metav1.Now()
+	var events []kuberecoveryv1alpha1.RetentionEventT
+
+	// Holds are recorded when the label is set or removed, as the status keeps the current one
+	held := isHeld(resource)
+	if held != (resource.Status.Hold != nil) {
+		event := kuberecoveryv1alpha1.RetentionEventT{
+			Time:        now,
+			Action:      retentionActionHeld,
+			RequestedBy: r.getRetentionRequester(resource, recoveryResourceHoldLabel, recoveryResourceHoldByAnnotation),
+		}
+		if held {
+			logger.Info(fmt.Sprintf(resourceHeldMessage, resource.Name, event.RequestedBy))
+		} else {
+			event.Action = retentionActionReleased
+			logger.Info(fmt.Sprintf(resourceReleasedMessage, resource.Name, event.RequestedBy))
+		}
+		events = append(events, event)
+	}
+
+	extension, requested := resource.GetLabels()[recoveryResourceExtendLabel]
+	if !requested {
+		return events, nil
+	}
+	requestedBy := r.getRetentionRequester(resource, recoveryResourceExtendLabel, recoveryResourceExtendByAnnotation)
+
+	// The extension label is removed once applied, so the retention is extended just once
+	delete(resource.GetLabels(), recoveryResourceExtendLabel)
+
+	duration, err := parseDurationWithDays(extension)
+	if err == nil && duration <= 0 {
+		err = fmt.Errorf(parseDurationWithDaysError, extension, "it must be positive")
+	}
+	if err != nil {
+		logger.Info(fmt.Sprintf(invalidRetentionExtensionMessage, extension, resource.Name, err))
+	} else {
+		retentionUntil, err := time.Parse(timeParseFormat, resource.GetLabels()[recoveryResourceRetainUntilLabel])
+		if err != nil {
+			return events, fmt.Errorf(timeParseError, err)
+		}
+
+		// Expired retentions are extended from now, so the resource is always kept for the whole extension
+		if now.UTC().After(retentionUntil) {
+			retentionUntil = now.UTC()
+		}
+		retentionUntil = retentionUntil.Add(duration)
+		resource.Labels[recoveryResourceRetainUntilLabel] = retentionUntil.Format(timeParseFormat)
+		r.expirations.forget(resource.Name)
+
+		// Extended captures do not follow the retention period of their RecoveryConfig anymore
+		if resource.Annotations == nil {
+			resource.Annotations = map[string]string{}
+		}
+		resource.Annotations[recoveryResourceRetentionSourceAnnotation] = retentionSourceManual
+
+		events = append(events, kuberecoveryv1alpha1.RetentionEventT{
+			Time:           now,
+			Action:         retentionActionExtended,
+			RequestedBy:    requestedBy,
+			Extension:      extension,
+			RetentionUntil: resource.Labels[recoveryResourceRetainUntilLabel],
+		})
+		logger.Info(fmt.Sprintf(retentionExtendedMessage, resource.Name, extension,
+			resource.Labels[recoveryResourceRetainUntilLabel], requestedBy))
+	}
+
+	err = r.Update(ctx, resource)
+	if err != nil {
+		return nil, fmt.Errorf(updateRetentionError, resource.Name, err)
+	}
+
+	return events, nil
+}
+
+// recordRetentionEvents adds the retention actions to the audit trail in the status of the RecoveryResource,
+// keeping the last ones, and sets the hold of the resource
+func recordRetentionEvents(resource *kuberecoveryv1alpha1.RecoveryResource,
+	events []kuberecoveryv1alpha1.RetentionEventT) {
+
+	for _, event := range events {
+		switch event.Action {
+		case retentionActionHeld:
+			resource.Status.Hold = event.DeepCopy()
+		case retentionActionReleased:
+			resource.Status.Hold = nil
+		}
+		resource.Status.RetentionHistory = append(resource.Status.RetentionHistory, event)
+	}
+
+	if len(resource.Status.RetentionHistory) > maxRetentionHistory {
+		resource.Status.RetentionHistory = resource.Status.RetentionHistory[len(resource.Status.RetentionHistory)-
+			maxRetentionHistory:]
+	}
+}
+
+// getRetentionRequester returns who changed the retention label of the RecoveryResource: the user recorded by the
+// retention audit webhook when it is served, as then no one else can set the annotation, or the field manager of the
+// label otherwise. Removed labels have no field manager, so the last manager that changed the RecoveryResource
+// is returned for them
+func (r *RecoveryResourceReconciler) getRetentionRequester(resource *kuberecoveryv1alpha1.RecoveryResource,
+	label, annotation string) string {
+
+	if r.retentionAuditWebhookEnabled {
+		if requestedBy := resource.GetAnnotations()[annotation]; requestedBy != "" {
+			return requestedBy
+		}
+	}
+	return getFieldManagerRequester(resource, label)
+}
+
+// purge deletes the RecoveryResource before its expiration, as requested in its purge label, removing its protect
+// finalizer and its payload. Held resources are not purged until they are released, and the label is removed.
+// Both outcomes are emitted as events of the RecoveryResource, as audit trail of who purged it
+func (r *RecoveryResourceReconciler) purge(ctx context.Context,
+	resource *kuberecoveryv1alpha1.RecoveryResource) error {
+
+	logger := log.FromContext(ctx)
+	requestedBy := r.getRetentionRequester(resource, recoveryResourcePurgeLabel, recoveryResourcePurgeByAnnotation)
+
+	if isHeld(resource) {
+		heldBy := ""
+		if resource.Status.Hold != nil {
+			heldBy = resource.Status.Hold.RequestedBy
+		}
+		message := fmt.Sprintf(purgeHeldRejectedMessage, resource.Name, requestedBy, heldBy)
+		logger.Info(message)
+		r.Recorder.Event(resource, corev1.EventTypeWarning, purgeEventReasonRejected, message)
+
+		delete(resource.GetLabels(), recoveryResourcePurgeLabel)
+		err := r.Update(ctx, resource)
+		if err != nil {
+			return fmt.Errorf(deletePurgeLabelError, resource.Name, err)
+		}
+		return nil
+	}
+
+	message := fmt.Sprintf(resourcePurgedMessage, resource.Name, requestedBy,
+		resource.GetLabels()[recoveryResourceRetainUntilLabel])
+	logger.Info(message)
+
+	// The payload is deleted once the RecoveryResource is finalized, so it is never left pointing to a deleted one
+	if controllerutil.ContainsFinalizer(resource, recoveryResourceExtraFinalizer) {
+		controllerutil.RemoveFinalizer(resource, recoveryResourceExtraFinalizer)
+		err := r.Update(ctx, resource)
+		if err != nil {
+			return fmt.Errorf(deleteExtraFinalizerError, resource.Name, err)
+		}
+	}
+	err := r.Delete(ctx, resource)
+	if err != nil {
+		return fmt.Errorf(resourceDeleteError, resource.Name, err)
+	}
+	r.expirations.forget(resource.Name)
+	r.Recorder.Event(resource, corev1.EventTypeNormal, purgeEventReasonPurged, message)
+
+	return nil
+}
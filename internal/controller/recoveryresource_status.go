@@ -44,3 +44,22 @@ func (r *RecoveryResourceReconciler) UpdateConditionKubernetesApiCallFailure(res
 	// Update the status of the QueryConnector resource
 	globals.UpdateCondition(&resource.Status.Conditions, condition)
 }
+
+// UpdateConditionRestoreVerification updates the status of the resource with the result of the verification
+// of the restored resource. It is unknown while the verification is pending
+func (r *RecoveryResourceReconciler) UpdateConditionRestoreVerification(resource *kuberecoveryv1alpha1.RecoveryResource,
+	verification *kuberecoveryv1alpha1.RestoreVerificationT) {
+
+	status := metav1.ConditionFalse
+	switch verification.Result {
+	case verificationResultPending:
+		status = metav1.ConditionUnknown
+	case verificationResultHealthy:
+		status = metav1.ConditionTrue
+	}
+
+	condition := globals.NewCondition(globals.ConditionTypeRestoreVerified, status,
+		verification.Result, verification.Message)
+
+	globals.UpdateCondition(&resource.Status.Conditions, condition)
+}
@@ -18,23 +18,17 @@ package controller
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"k8s.io/client-go/dynamic"
 	"time"
 
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
-	"freepik.com/kuberecovery/internal/globals"
 )
 
-// Sync checks if the resource is expired and deletes it if it is
-// Also recreate the resource if it has a specific label
+// Sync checks if the resource is expired and deletes it if it is, as long as the deletion rate allows it and
+// it is not held. Also extends its retention, purges it and recreates the resource when it has the specific labels
 func (r *RecoveryResourceReconciler) Sync(ctx context.Context,
 	resource *kuberecoveryv1alpha1.RecoveryResource) (err error) {
 
@@ -49,6 +43,22 @@ func (r *RecoveryResourceReconciler) Sync(ctx context.Context,
 		}
 	}
 
+	// Apply the holds and the extensions of the retention requested by hand. They are recorded in the status
+	// once the rest of the sync is done, as the updates of the resource overwrite it with the stored one
+	var retentionEvents []kuberecoveryv1alpha1.RetentionEventT
+	defer func() {
+		recordRetentionEvents(resource, retentionEvents)
+	}()
+	retentionEvents, err = r.syncRetentionRequests(ctx, resource)
+	if err != nil {
+		return err
+	}
+
+	// Purge the resource before its expiration when it is requested by hand
+	if resource.GetLabels()[recoveryResourcePurgeLabel] == recoveryResourcePurgeLabelValue {
+		return r.purge(ctx, resource)
+	}
+
 	// Get validUntil label from the resource
 	validUntilLabel := resource.GetLabels()[recoveryResourceRetainUntilLabel]
 
@@ -58,11 +68,19 @@ func (r *RecoveryResourceReconciler) Sync(ctx context.Context,
 		return fmt.Errorf(timeParseError, err)
 	}
 
-	// Check if the resource is expired
-	if time.Now().UTC().After(validUntil) {
+	// Check if the resource is expired. Held resources are kept until they are released
+	if time.Now().UTC().After(validUntil) && isHeld(resource) {
+		logger.Info(fmt.Sprintf(resourceExpiredHeldMessage, resource.Name))
+	} else if time.Now().UTC().After(validUntil) {
+
+		// Expired resources are deleted at the configured rate, so a mass expiry is spread over time
+		if !r.expirations.reserve(resource.Name) {
+			logger.Info(fmt.Sprintf(resourceExpirationDelayedMessage, resource.Name))
+			return nil
+		}
 
 		// If the resource is expired, delete it.
-		// First remove the finalizer and then delete the resource
+		// First remove the finalizer and then delete the resource. Its payload is deleted once it is finalized
 		logger.Info(fmt.Sprintf(resourceExpiredMessage, resource.Name))
 		if controllerutil.ContainsFinalizer(resource, recoveryResourceExtraFinalizer) {
 			controllerutil.RemoveFinalizer(resource, recoveryResourceExtraFinalizer)
@@ -79,55 +97,70 @@ func (r *RecoveryResourceReconciler) Sync(ctx context.Context,
 		return nil
 	}
 
-	// Get restore label trigger. If it is present, restore the resource
+	// Check the health of the resource restored before, while it is being verified
+	if isRestoreVerificationPending(resource) {
+		err = r.verifyRestore(ctx, resource)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Get restore label trigger. If it is present, restore the resource, or just validate the restore on dry runs
 	restoreTriggerLabel := resource.GetLabels()[recoveryResourceRestoreLabel]
-	if restoreTriggerLabel == recoveryResourceRestoreLabelValue {
-		logger.Info(fmt.Sprintf(resourceRestoreMessage, resource.Name))
+	dryRun := restoreTriggerLabel == recoveryResourceRestoreDryRunValue
+	if restoreTriggerLabel == recoveryResourceRestoreLabelValue || dryRun {
+		logger.Info(fmt.Sprintf(resourceRestoreMessage, resource.Name, restoreTriggerLabel))
 
-		// Remove the restore label to avoid restoring the resource again
+		// Remove the restore label to avoid restoring the resource again, and record the result of the restore.
+		// The status is set after the update, as the update overwrites it with the stored one
+		var lastRestore *kuberecoveryv1alpha1.LastRestoreT
 		defer func() {
 			delete(resource.GetLabels(), recoveryResourceRestoreLabel)
-			err = r.Update(ctx, resource)
+			err := r.Update(ctx, resource)
 			if err != nil {
 				logger.Info(fmt.Sprintf(deleteRestoreLabelError, resource.Name, err))
 			}
+			setLastRestore(resource, lastRestore)
+			if lastRestore != nil && lastRestore.Verification != nil {
+				r.UpdateConditionRestoreVerification(resource, lastRestore.Verification)
+			}
 		}()
 
-		// Unmarshal the RawExtension to the object to get unstructured.Unstructured
-		var resourceToRestore unstructured.Unstructured
-		if err := json.Unmarshal(resource.Spec.Raw, &resourceToRestore.Object); err != nil {
-			return fmt.Errorf(deserializingRawExtensionError, err)
-		}
-
-		// Create the GVR for the RecoveryResource
-		res, err := getResourceFromKind(resourceToRestore.GroupVersionKind().Group,
-			resourceToRestore.GroupVersionKind().Version, resourceToRestore.GroupVersionKind().Kind)
+		// The verification of the restored resource is requested in the annotations of the RecoveryResource
+		var verifyTimeout time.Duration
+		var verify bool
+		verifyTimeout, verify, err = getRestoreVerificationTimeout(resource)
 		if err != nil {
-			return fmt.Errorf(getResourceFromKindError, err)
-		}
-		gvr := schema.GroupVersionResource{
-			Group:    resourceToRestore.GroupVersionKind().Group,
-			Version:  resourceToRestore.GroupVersionKind().Version,
-			Resource: res,
+			return err
 		}
 
-		// Create the dynamic client for the RecoveryResource for namespaced and cluster-scoped resources
-		var dynamicClient dynamic.ResourceInterface
-		if resourceToRestore.GetNamespace() != "" {
-			dynamicClient = globals.Application.KubeRawClient.Resource(gvr).Namespace(resourceToRestore.GetNamespace())
-		} else {
-			dynamicClient = globals.Application.KubeRawClient.Resource(gvr)
+		// Create the resource saved in the RecoveryResource, from the spec or from the storage backend,
+		// with the overrides set in its annotations.
+		// Revisions roll the live resource back to the saved state, so they replace it by default when it exists
+		defaultStrategy := conflictStrategyFail
+		if resource.GetLabels()[recoveryResourceCaptureReasonLabel] == captureReasonUpdate {
+			defaultStrategy = conflictStrategyReplace
 		}
-
-		// Create the resource saved in the RecoveryResource spec
-		_, err = dynamicClient.Create(ctx, &resourceToRestore, metav1.CreateOptions{})
+		lastRestore, err = restoreRecoveryResource(ctx, r.StorageBackend, resource, restoreRequest{
+			defaultStrategy: defaultStrategy,
+			dryRun:          dryRun,
+		})
 		if err != nil {
-			return fmt.Errorf(createResourceError, resourceToRestore.GetName(), err)
+			return err
 		}
 
+		if dryRun {
+			logger.Info(fmt.Sprintf(resourceRestoreDryRunMessage, resource.Name,
+				lastRestore.Namespace, lastRestore.Name, lastRestore.Result, len(lastRestore.Diff)))
+			return nil
+		}
 		logger.Info(fmt.Sprintf(resourceRestoredSuccessfullyMessage, resource.Name,
-			resourceToRestore.GroupVersionKind().Group, resourceToRestore.GroupVersionKind().Version,
-			resourceToRestore.GroupVersionKind().Kind, resourceToRestore.GetNamespace(), resourceToRestore.GetName()))
+			lastRestore.Namespace, lastRestore.Name, lastRestore.Result))
+
+		// The health of the restored resource is checked in the next syncs, until the verification finishes
+		if verify && isVerifiedResult(lastRestore.Result) {
+			lastRestore.Verification = newRestoreVerification(verifyTimeout)
+		}
 	}
 
 	return nil
@@ -0,0 +1,122 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const (
+	retentionAuditWebhookPath = "/mutate-retention-audit"
+)
+
+// retentionRequestAnnotations are the annotations recording who changed every retention label
+var retentionRequestAnnotations = map[string]string{
+	recoveryResourceHoldLabel:   recoveryResourceHoldByAnnotation,
+	recoveryResourceExtendLabel: recoveryResourceExtendByAnnotation,
+	recoveryResourcePurgeLabel:  recoveryResourcePurgeByAnnotation,
+}
+
+// +kubebuilder:webhook:path=/mutate-retention-audit,mutating=true,failurePolicy=fail,sideEffects=None,groups=kuberecovery.freepik.com,resources=recoveryresources,verbs=create;update,versions=v1alpha1,name=mretentionaudit.kuberecovery.freepik.com,admissionReviewVersions=v1,timeoutSeconds=5
+
+// retentionAuditWebhook records the user changing the hold, retention extension or purge labels of a RecoveryResource
+// in its annotations, so the audit trail shows who requested them. The annotations can not be set nor changed by the
+// users: they are overwritten on every request, so the controller trusts them while the webhook is served
+type retentionAuditWebhook struct{}
+
+// SetupWebhookWithManager registers the retention audit webhook in the webhook server of the Manager
+func (r *RecoveryResourceReconciler) SetupWebhookWithManager(mgr ctrl.Manager) {
+	r.retentionAuditWebhookEnabled = true
+	mgr.GetWebhookServer().Register(retentionAuditWebhookPath, &webhook.Admission{
+		Handler: &retentionAuditWebhook{},
+	})
+}
+
+// Handle annotates the RecoveryResource with the user of the request for every retention label it changes,
+// and keeps the previous values of the annotations of the labels it does not change
+func (w *retentionAuditWebhook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.SubResource != "" {
+		return admission.Allowed("")
+	}
+
+	obj := &unstructured.Unstructured{}
+	err := obj.UnmarshalJSON(req.Object.Raw)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf(decodeAdmissionObjectError, req.UID, err))
+	}
+	oldObj := &unstructured.Unstructured{}
+	if req.Operation == admissionv1.Update {
+		err = oldObj.UnmarshalJSON(req.OldObject.Raw)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, fmt.Errorf(decodeAdmissionObjectError, req.UID, err))
+		}
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+
+	changed := false
+	for label, annotation := range retentionRequestAnnotations {
+		requestedBy, requested := oldObj.GetAnnotations()[annotation]
+		if isRetentionRequest(label, obj, oldObj) {
+			requestedBy, requested = req.UserInfo.Username, true
+		}
+
+		current, exists := annotations[annotation]
+		if current == requestedBy && exists == requested {
+			continue
+		}
+		if requested {
+			annotations[annotation] = requestedBy
+		} else {
+			delete(annotations, annotation)
+		}
+		changed = true
+	}
+	if !changed {
+		return admission.Allowed("")
+	}
+
+	obj.SetAnnotations(annotations)
+	mutated, err := obj.MarshalJSON()
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, mutated)
+}
+
+// isRetentionRequest returns true if the request sets or changes the retention label of the RecoveryResource.
+// The extension and purge labels are removed by the controller once they are handled, which is not a request,
+// while removing the hold label releases the RecoveryResource
+func isRetentionRequest(label string, obj, oldObj *unstructured.Unstructured) bool {
+	value, exists := obj.GetLabels()[label]
+	oldValue, oldExists := oldObj.GetLabels()[label]
+	if value == oldValue && exists == oldExists {
+		return false
+	}
+	return exists || label == recoveryResourceHoldLabel
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/storage"
+)
+
+// RecoveryRestoreReconciler reconciles a RecoveryRestore object
+type RecoveryRestoreReconciler struct {
+	client.Client
+	Scheme         *runtime.Scheme
+	StorageBackend storage.Backend
+
+	// requesterWebhookEnabled is set when the restore requester webhook is served, so its annotation is trusted
+	requesterWebhookEnabled bool
+}
+
+// +kubebuilder:rbac:groups=kuberecovery.freepik.com,resources=recoveryrestores,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=kuberecovery.freepik.com,resources=recoveryrestores/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=kuberecovery.freepik.com,resources=recoveryrestores/finalizers,verbs=update
+
+// Reconcile is part of the main kubernetes reconciliation loop which aims to
+// move the current state of the cluster closer to the desired state.
+//
+// For more details, check Reconcile and its Result here:
+// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.19.1/pkg/reconcile
+func (r *RecoveryRestoreReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	logger := log.FromContext(ctx)
+
+	// 1. Get the content of the RecoveryRestore
+	restore := &kuberecoveryv1alpha1.RecoveryRestore{}
+	err = r.Get(ctx, req.NamespacedName, restore)
+
+	// 2. Check existence on the cluster
+	if err != nil {
+
+		// 2.1 It does NOT exist: nothing to do
+		if err = client.IgnoreNotFound(err); err == nil {
+			logger.Info(fmt.Sprintf(resourceNotFoundError, recoveryRestoreType, req.NamespacedName))
+			return result, err
+		}
+
+		// 2.2 Failed to get the resource, requeue the request
+		logger.Info(fmt.Sprintf(resourceSyncTimeRetrievalError, recoveryRestoreType, req.NamespacedName, err.Error()))
+		return result, err
+	}
+
+	// 3. The restore is done just once, the RecoveryRestore is kept as a record of it
+	if restore.Status.CompletionTime != nil {
+		return result, nil
+	}
+
+	// 4. Update the status before leaving
+	defer func() {
+		statusErr := r.Status().Update(ctx, restore)
+		if statusErr != nil {
+			logger.Info(fmt.Sprintf(resourceConditionUpdateError, recoveryRestoreType, req.NamespacedName, statusErr.Error()))
+		}
+	}()
+
+	// 5. Restore the selected RecoveryResources
+	err = r.Sync(ctx, restore)
+	if err != nil {
+		r.UpdateConditionKubernetesApiCallFailure(restore)
+		logger.Info(fmt.Sprintf(syncTargetError, recoveryRestoreType, req.NamespacedName, err.Error()))
+		return result, err
+	}
+
+	// 6. Success, update the status. The resources that could not be restored are reported in the condition,
+	// without requeueing the request, as they are not restored again
+	if failed := countFailedItems(restore.Status.Items); failed > 0 {
+		r.UpdateConditionPartialFailure(restore, failed)
+		return result, nil
+	}
+	r.UpdateConditionSuccess(restore)
+
+	return result, err
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *RecoveryRestoreReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&kuberecoveryv1alpha1.RecoveryRestore{}).
+		WithEventFilter(predicate.GenerationChangedPredicate{}).
+		Named("recoveryrestore").
+		Complete(r)
+}
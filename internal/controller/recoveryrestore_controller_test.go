@@ -0,0 +1,160 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/globals"
+)
+
+func TestRecoveryRestoreInterrupted(t *testing.T) {
+	tests := []struct {
+		name string
+
+		// Status updates of the RecoveryRestore done before the first reconcile is interrupted, 0 not to interrupt it
+		interruptAfter int
+
+		expectedResults   []string
+		expectedCondition string
+	}{
+		{
+			name:              "restore completed on the first reconcile",
+			expectedResults:   []string{restoreResultCreated, restoreResultCreated},
+			expectedCondition: globals.ConditionReasonTargetSynced,
+		},
+		{
+			name:              "restore interrupted while restoring the first resource",
+			interruptAfter:    1,
+			expectedResults:   []string{restoreResultFailed, restoreResultCreated},
+			expectedCondition: globals.ConditionReasonRestorePartiallyFailed,
+		},
+		{
+			name:              "restore interrupted after restoring the first resource",
+			interruptAfter:    2,
+			expectedResults:   []string{restoreResultCreated, restoreResultCreated},
+			expectedCondition: globals.ConditionReasonTargetSynced,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			dynamicClient := setupFakeClients(t)
+
+			scheme := runtime.NewScheme()
+			if err := kuberecoveryv1alpha1.AddToScheme(scheme); err != nil {
+				t.Fatalf("unexpected error building the scheme: %v", err)
+			}
+
+			savedAt := time.Now().UTC().Format(timeParseFormat)
+			objects := []client.Object{&kuberecoveryv1alpha1.RecoveryRestore{
+				ObjectMeta: metav1.ObjectMeta{Name: "restore-sample"},
+				Spec: kuberecoveryv1alpha1.RecoveryRestoreSpec{
+					Selector: kuberecoveryv1alpha1.RecoveryRestoreSelectorT{Namespaces: []string{"default"}},
+				},
+			}}
+			for _, name := range []string{"settings", "theme"} {
+				objects = append(objects, &kuberecoveryv1alpha1.RecoveryResource{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   fmt.Sprintf("recoveryconfig-sample-configmap-%s", name),
+						Labels: map[string]string{recoveryResourceSavedAtLabel: savedAt},
+					},
+					Spec: runtime.RawExtension{Raw: []byte(fmt.Sprintf(
+						`{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"%s","namespace":"default"}}`, name))},
+				})
+			}
+
+			// The status updates of the RecoveryRestore fail once interrupted, as if the operator was restarted
+			statusUpdates := 0
+			interrupted := false
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).
+				WithStatusSubresource(objects...).WithInterceptorFuncs(interceptor.Funcs{
+				SubResourceUpdate: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object,
+					opts ...client.SubResourceUpdateOption) error {
+					if _, isRestore := obj.(*kuberecoveryv1alpha1.RecoveryRestore); isRestore && interrupted {
+						return errors.New("operator restarted")
+					}
+					if _, isRestore := obj.(*kuberecoveryv1alpha1.RecoveryRestore); isRestore {
+						statusUpdates++
+						interrupted = statusUpdates == test.interruptAfter
+					}
+					return c.SubResource(subResourceName).Update(ctx, obj, opts...)
+				},
+			}).Build()
+			reconciler := &RecoveryRestoreReconciler{Client: fakeClient, Scheme: scheme}
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: "restore-sample"}}
+			_, _ = reconciler.Reconcile(ctx, request)
+
+			interrupted = false
+			_, err := reconciler.Reconcile(ctx, request)
+			if err != nil {
+				t.Fatalf("unexpected error reconciling the restore again: %v", err)
+			}
+			_, err = reconciler.Reconcile(ctx, request)
+			if err != nil {
+				t.Fatalf("unexpected error reconciling the completed restore: %v", err)
+			}
+
+			creates := 0
+			for _, action := range dynamicClient.Actions() {
+				if action.GetVerb() == "create" {
+					creates++
+				}
+			}
+			if creates != 2 {
+				t.Errorf("expected every resource to be created once, got %d creates", creates)
+			}
+
+			restore := &kuberecoveryv1alpha1.RecoveryRestore{}
+			if err = fakeClient.Get(ctx, request.NamespacedName, restore); err != nil {
+				t.Fatalf("unexpected error getting the restore: %v", err)
+			}
+			if restore.Status.CompletionTime == nil {
+				t.Errorf("expected the restore to be completed")
+			}
+			if len(restore.Status.Items) != len(test.expectedResults) {
+				t.Fatalf("expected %d items, got %d", len(test.expectedResults), len(restore.Status.Items))
+			}
+			for i, item := range restore.Status.Items {
+				if item.Result != test.expectedResults[i] {
+					t.Errorf("item %d: expected result %s, got %s", i, test.expectedResults[i], item.Result)
+				}
+			}
+
+			condition := apimeta.FindStatusCondition(restore.Status.Conditions, globals.ConditionTypeResourceSynced)
+			if condition == nil || condition.Reason != test.expectedCondition {
+				t.Errorf("expected the condition reason %s, got %v", test.expectedCondition, condition)
+			}
+		})
+	}
+}
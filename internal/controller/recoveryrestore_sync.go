@@ -0,0 +1,223 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+// Sync restores the resources saved in every RecoveryResource selected by the RecoveryRestore. The result of every one
+// is recorded in the status as soon as it is restored, so a restore is never done twice for the same RecoveryResource
+func (r *RecoveryRestoreReconciler) Sync(ctx context.Context,
+	resource *kuberecoveryv1alpha1.RecoveryRestore) (err error) {
+
+	logger := log.FromContext(ctx)
+
+	if resource.Status.StartTime == nil {
+		now := metav1.Now()
+		resource.Status.StartTime = &now
+		resource.Status.RequestedBy = r.getRestoreRequester(resource)
+	}
+
+	// Get the RecoveryResources matching the selector
+	candidates, err := r.getRestoreCandidates(ctx, resource)
+	if err != nil {
+		return err
+	}
+	logger.Info(fmt.Sprintf(restoreSelectedMessage, resource.Name, len(candidates)))
+
+	// Restore all of them in dependency order, reporting the result of each one. The UIDs restored by a previous
+	// run of an interrupted restore are kept, so the ownerReferences are still rewired to them
+	uidMapping := maps.Clone(resource.Status.UIDMapping)
+	if uidMapping == nil {
+		uidMapping = make(map[string]string)
+	}
+	recordStatus := func() error {
+		resource.Status.UIDMapping = uidMapping
+		err := r.Status().Update(ctx, resource)
+		if err != nil {
+			return fmt.Errorf(recordRestoreProgressError, resource.Name, err)
+		}
+		return nil
+	}
+
+	err = restoreCandidates(candidates, &resource.Status.Items, recordStatus,
+		func(candidate recoveryCandidate) kuberecoveryv1alpha1.RestoredItemT {
+			item := newRestoredItem(candidate.recoveryResource, candidate.identity)
+
+			lastRestore, _ := restoreRecoveryResource(ctx, r.StorageBackend, candidate.recoveryResource, restoreRequest{
+				overrides:        getCandidateOverrides(candidate, &resource.Spec),
+				conflictStrategy: resource.Spec.ConflictStrategy,
+				defaultStrategy:  conflictStrategyFail,
+				dryRun:           resource.Spec.DryRun,
+				uidMapping:       uidMapping,
+			})
+			updateLastRestore(ctx, r.Client, candidate.recoveryResource, lastRestore)
+			recordUIDMapping(uidMapping, candidate.recoveryResource, lastRestore)
+
+			item.ConflictStrategy = lastRestore.ConflictStrategy
+			item.Result = lastRestore.Result
+			item.Message = lastRestore.Message
+			item.Diff = lastRestore.Diff
+			item.Steps = lastRestore.Steps
+			return item
+		})
+	if err != nil {
+		return err
+	}
+
+	// The restore is completed also when some resources failed, as they are reported in their items
+	now := metav1.Now()
+	resource.Status.UIDMapping = uidMapping
+	resource.Status.CompletionTime = &now
+
+	return nil
+}
+
+// getRestoreCandidates returns the RecoveryResources of deleted resources matching the selector of the
+// RecoveryRestore, in restore order and after their owners. When a resource was deleted several times, just its last deletion is restored
+func (r *RecoveryRestoreReconciler) getRestoreCandidates(ctx context.Context,
+	resource *kuberecoveryv1alpha1.RecoveryRestore) (candidates []recoveryCandidate, err error) {
+
+	logger := log.FromContext(ctx)
+
+	recoveryResources := &kuberecoveryv1alpha1.RecoveryResourceList{}
+	err = r.List(ctx, recoveryResources)
+	if err != nil {
+		return nil, fmt.Errorf(listRecoveryResourcesError, err)
+	}
+
+	selected := make(map[string]recoveryCandidate)
+	for i := range recoveryResources.Items {
+		recoveryResource := &recoveryResources.Items[i]
+
+		// Revisions are the previous states of live resources, not deletions
+		if recoveryResource.GetLabels()[recoveryResourceCaptureReasonLabel] == captureReasonUpdate {
+			continue
+		}
+
+		savedAt, err := getSavedAt(recoveryResource)
+		if err != nil {
+			logger.Info(fmt.Sprintf(parseRecoveryResourceError, recoveryResource.Name, err))
+			continue
+		}
+
+		identity, err := getRecoveryResourceIdentity(recoveryResource)
+		if err != nil {
+			logger.Info(fmt.Sprintf(parseRecoveryResourceError, recoveryResource.Name, err))
+			continue
+		}
+
+		candidate := recoveryCandidate{
+			recoveryResource: recoveryResource,
+			identity:         identity,
+			savedAt:          savedAt,
+		}
+		matches, err := isRestoreSelected(candidate, resource.Spec.Selector, resource.Spec.Cascade)
+		if err != nil {
+			return nil, err
+		}
+		if !matches {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%s/%s/%s", identity.GetAPIVersion(), identity.GetKind(),
+			identity.GetNamespace(), identity.GetName())
+		if current, exists := selected[key]; !exists || current.savedAt.Before(savedAt) {
+			selected[key] = candidate
+		}
+	}
+
+	for _, candidate := range selected {
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].recoveryResource.Name < candidates[j].recoveryResource.Name
+	})
+	sortByRestoreOrder(candidates, resource.Spec.KindOrder)
+	sortByOwnership(candidates)
+
+	return candidates, nil
+}
+
+// isRestoreSelected checks if the RecoveryResource matches every field set in the selector.
+// On cascade restores, the Namespace objects of the selected namespaces are selected too
+func isRestoreSelected(candidate recoveryCandidate, selector kuberecoveryv1alpha1.RecoveryRestoreSelectorT,
+	cascade bool) (bool, error) {
+
+	identity := candidate.identity
+
+	if len(selector.RecoveryConfigs) > 0 && !slices.Contains(selector.RecoveryConfigs,
+		getCaptureRecoveryConfig(candidate.recoveryResource)) {
+		return false, nil
+	}
+
+	if selector.DeletedAfter != nil && candidate.savedAt.Before(selector.DeletedAfter.UTC()) {
+		return false, nil
+	}
+
+	if selector.DeletedBefore != nil && candidate.savedAt.After(selector.DeletedBefore.UTC()) {
+		return false, nil
+	}
+
+	if cascade && isNamespaceObject(identity) && slices.Contains(selector.Namespaces, identity.GetName()) {
+		return true, nil
+	}
+
+	if len(selector.Namespaces) > 0 && !slices.Contains(selector.Namespaces, identity.GetNamespace()) {
+		return false, nil
+	}
+
+	if len(selector.Kinds) > 0 && !slices.Contains(selector.Kinds, identity.GetKind()) {
+		return false, nil
+	}
+
+	if len(selector.Names) == 0 {
+		return true, nil
+	}
+	for _, name := range selector.Names {
+		matches, err := matchPattern(name, identity.GetName())
+		if err != nil {
+			return false, fmt.Errorf(invalidPatternError, name, err)
+		}
+		if matches {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// getCandidateOverrides returns the overrides of the RecoveryRestore applied to the candidate. The Namespace objects
+// restored on cascade are just renamed to the namespace the resources are restored into, if it is overridden
+func getCandidateOverrides(candidate recoveryCandidate,
+	spec *kuberecoveryv1alpha1.RecoveryRestoreSpec) *kuberecoveryv1alpha1.RestoreOverridesT {
+
+	if spec.Cascade && isNamespaceObject(candidate.identity) {
+		return &kuberecoveryv1alpha1.RestoreOverridesT{Name: spec.Overrides.Namespace}
+	}
+	return &spec.Overrides
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/globals"
+)
+
+const (
+	restoreRequesterWebhookPath = "/mutate-recoveryrestore-requester"
+)
+
+// +kubebuilder:rbac:groups=authorization.k8s.io,resources=subjectaccessreviews,verbs=create
+
+// +kubebuilder:webhook:path=/mutate-recoveryrestore-requester,mutating=true,failurePolicy=fail,sideEffects=None,groups=kuberecovery.freepik.com,resources=recoveryrestores,verbs=create;update,versions=v1alpha1,name=mrecoveryrestore.kuberecovery.freepik.com,admissionReviewVersions=v1,timeoutSeconds=5
+
+// restoreRequesterWebhook records the user creating a RecoveryRestore in its requestedBy annotation, so the restore
+// is audited with who requested it. The annotation can not be set nor changed by the users: it is overwritten on
+// creation and kept on updates. The resources are restored with the permissions of the operator, so the RecoveryRestore
+// is rejected when the user could not restore the selected resources by themselves
+type restoreRequesterWebhook struct{}
+
+// restoreAccess is an access the user requesting a RecoveryRestore must have. An empty namespace is every namespace
+type restoreAccess struct {
+	verb      string
+	namespace string
+	resource  schema.GroupResource
+}
+
+// SetupWebhookWithManager registers the restore requester webhook in the webhook server of the Manager
+func (r *RecoveryRestoreReconciler) SetupWebhookWithManager(mgr ctrl.Manager) {
+	r.requesterWebhookEnabled = true
+	mgr.GetWebhookServer().Register(restoreRequesterWebhookPath, &webhook.Admission{
+		Handler: &restoreRequesterWebhook{},
+	})
+}
+
+// Handle sets the requestedBy annotation of the RecoveryRestore to the user creating it, or to its previous value
+func (w *restoreRequesterWebhook) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.SubResource != "" {
+		return admission.Allowed("")
+	}
+
+	obj := &unstructured.Unstructured{}
+	err := obj.UnmarshalJSON(req.Object.Raw)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf(decodeAdmissionObjectError, req.UID, err))
+	}
+
+	response := reviewRestoreAccess(ctx, req)
+	if !response.Allowed {
+		return response
+	}
+
+	requestedBy, requested := req.UserInfo.Username, true
+	if req.Operation == admissionv1.Update {
+		oldObj := &unstructured.Unstructured{}
+		err = oldObj.UnmarshalJSON(req.OldObject.Raw)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, fmt.Errorf(decodeAdmissionObjectError, req.UID, err))
+		}
+		requestedBy, requested = oldObj.GetAnnotations()[recoveryRestoreRequestedByAnnotation]
+	}
+
+	current, exists := obj.GetAnnotations()[recoveryRestoreRequestedByAnnotation]
+	if current == requestedBy && exists == requested {
+		return admission.Allowed("")
+	}
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if requested {
+		annotations[recoveryRestoreRequestedByAnnotation] = requestedBy
+	} else {
+		delete(annotations, recoveryRestoreRequestedByAnnotation)
+	}
+	obj.SetAnnotations(annotations)
+
+	mutated, err := obj.MarshalJSON()
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, mutated)
+}
+
+// reviewRestoreAccess allows the RecoveryRestore when the user of the request has every access needed to restore
+// the selected resources by themselves. Updates not changing the spec are allowed, as they do not restore anything else
+func reviewRestoreAccess(ctx context.Context, req admission.Request) admission.Response {
+	restore := &kuberecoveryv1alpha1.RecoveryRestore{}
+	err := json.Unmarshal(req.Object.Raw, restore)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, fmt.Errorf(decodeAdmissionObjectError, req.UID, err))
+	}
+
+	if req.Operation == admissionv1.Update {
+		oldRestore := &kuberecoveryv1alpha1.RecoveryRestore{}
+		err = json.Unmarshal(req.OldObject.Raw, oldRestore)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, fmt.Errorf(decodeAdmissionObjectError, req.UID, err))
+		}
+		if equality.Semantic.DeepEqual(restore.Spec, oldRestore.Spec) {
+			return admission.Allowed("")
+		}
+	}
+
+	accesses, err := getRestoreAccesses(&restore.Spec)
+	if err != nil {
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	for _, access := range accesses {
+		allowed, err := reviewAccess(ctx, req.UserInfo, access)
+		if err != nil {
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+		if !allowed {
+			namespace := access.namespace
+			if namespace == "" {
+				namespace = allNamespacesDescription
+			}
+			return admission.Denied(fmt.Sprintf(restoreAccessDeniedMessage, req.UserInfo.Username, access.verb,
+				access.resource, namespace))
+		}
+	}
+
+	return admission.Allowed("")
+}
+
+// getRestoreAccesses returns the accesses needed to restore the resources selected by the RecoveryRestore:
+// getting them in the namespaces they were deleted from, and creating and updating them in the namespaces they are
+// restored into. Every namespace is checked when the selector does not set them, and every resource when it does not
+// set the kinds
+func getRestoreAccesses(spec *kuberecoveryv1alpha1.RecoveryRestoreSpec) (accesses []restoreAccess, err error) {
+	resources := []schema.GroupResource{{Group: "*", Resource: "*"}}
+	if len(spec.Selector.Kinds) > 0 {
+		resources, err = getKindsResources(spec.Selector.Kinds)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	sourceNamespaces := spec.Selector.Namespaces
+	if len(sourceNamespaces) == 0 {
+		sourceNamespaces = []string{""}
+	}
+	targetNamespaces := sourceNamespaces
+	if spec.Overrides.Namespace != "" {
+		targetNamespaces = []string{spec.Overrides.Namespace}
+	}
+
+	for _, resource := range resources {
+		for _, namespace := range sourceNamespaces {
+			accesses = append(accesses, restoreAccess{verb: "get", namespace: namespace, resource: resource})
+		}
+		for _, namespace := range targetNamespaces {
+			accesses = append(accesses,
+				restoreAccess{verb: "create", namespace: namespace, resource: resource},
+				restoreAccess{verb: "update", namespace: namespace, resource: resource})
+		}
+	}
+
+	// Namespace objects are restored too on cascade restores
+	if spec.Cascade {
+		accesses = append(accesses, restoreAccess{verb: "create", resource: schema.GroupResource{Resource: "namespaces"}})
+	}
+
+	return accesses, nil
+}
+
+// getKindsResources returns the resources of the kinds in every group served by the cluster. The kinds not served
+// are checked as every resource, as the resource they were served as is unknown
+func getKindsResources(kinds []string) (resources []schema.GroupResource, err error) {
+	_, resourceLists, err := globals.Application.KubeRawCoreClient.Discovery().ServerGroupsAndResources()
+	if err != nil && len(resourceLists) == 0 {
+		return nil, fmt.Errorf(listAPIResourcesError, err)
+	}
+
+	for _, kind := range kinds {
+		found := false
+		for _, resourceList := range resourceLists {
+			groupVersion, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+			if err != nil {
+				continue
+			}
+			for _, apiResource := range resourceList.APIResources {
+				if apiResource.Kind != kind || strings.Contains(apiResource.Name, "/") {
+					continue
+				}
+				resource := schema.GroupResource{Group: groupVersion.Group, Resource: apiResource.Name}
+				if !slices.Contains(resources, resource) {
+					resources = append(resources, resource)
+				}
+				found = true
+			}
+		}
+		if !found {
+			return []schema.GroupResource{{Group: "*", Resource: "*"}}, nil
+		}
+	}
+
+	return resources, nil
+}
+
+// reviewAccess checks with a SubjectAccessReview if the user has the access
+func reviewAccess(ctx context.Context, userInfo authenticationv1.UserInfo, access restoreAccess) (bool, error) {
+	extra := make(map[string]authorizationv1.ExtraValue, len(userInfo.Extra))
+	for key, value := range userInfo.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+
+	review := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   userInfo.Username,
+			UID:    userInfo.UID,
+			Groups: userInfo.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: access.namespace,
+				Verb:      access.verb,
+				Group:     access.resource.Group,
+				Resource:  access.resource.Resource,
+			},
+		},
+	}
+	review, err := globals.Application.KubeRawCoreClient.AuthorizationV1().SubjectAccessReviews().
+		Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return false, fmt.Errorf(reviewAccessError, access.verb, access.resource, access.namespace, err)
+	}
+	return review.Status.Allowed, nil
+}
+
+// getRestoreRequester returns who created the RecoveryRestore: the user recorded by the restore requester webhook
+// when it is served, as then no one else can set the annotation, or the field manager that set its spec otherwise
+func (r *RecoveryRestoreReconciler) getRestoreRequester(restore *kuberecoveryv1alpha1.RecoveryRestore) string {
+	if r.requesterWebhookEnabled {
+		if requestedBy, exists := restore.GetAnnotations()[recoveryRestoreRequestedByAnnotation]; exists {
+			return requestedBy
+		}
+	}
+	return getFieldManagerRequester(restore, "spec")
+}
+
+// getFieldManagerRequester returns the field manager owning the field of the object, as fallback of the user that
+// requested a change when the webhooks are not served. It is prefixed, as it is the name of a client, not a user
+func getFieldManagerRequester(obj metav1.Object, fieldName string) string {
+	manager := getFieldManager(obj, fieldName)
+	if manager == "" {
+		return ""
+	}
+	return fmt.Sprintf(fieldManagerRequesterFormat, manager)
+}
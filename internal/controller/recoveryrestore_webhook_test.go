@@ -0,0 +1,166 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubernetesfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/globals"
+)
+
+func TestRestoreRequesterWebhookAccessReview(t *testing.T) {
+	// Accesses of the user in the team namespace, as verb/namespace/resource
+	teamAccesses := []string{
+		"get/team/configmaps", "create/team/configmaps", "update/team/configmaps",
+		"get/team/secrets", "create/team/secrets", "update/team/secrets",
+	}
+	spec := func(namespaces, kinds []string, targetNamespace string) kuberecoveryv1alpha1.RecoveryRestoreSpec {
+		return kuberecoveryv1alpha1.RecoveryRestoreSpec{
+			Selector:  kuberecoveryv1alpha1.RecoveryRestoreSelectorT{Namespaces: namespaces, Kinds: kinds},
+			Overrides: kuberecoveryv1alpha1.RestoreOverridesT{Namespace: targetNamespace},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		operation admissionv1.Operation
+		oldSpec   kuberecoveryv1alpha1.RecoveryRestoreSpec
+		spec      kuberecoveryv1alpha1.RecoveryRestoreSpec
+		accesses  []string
+
+		expectedAllowed bool
+		expectedReviews int
+	}{
+		{
+			name:            "restore of the own namespace",
+			operation:       admissionv1.Create,
+			spec:            spec([]string{"team"}, []string{"ConfigMap", "Secret"}, ""),
+			accesses:        teamAccesses,
+			expectedAllowed: true,
+			expectedReviews: 6,
+		},
+		{
+			name:      "restore of the Secrets of another namespace into the own namespace",
+			operation: admissionv1.Create,
+			spec:      spec([]string{"payments"}, []string{"Secret"}, "team"),
+			accesses:  teamAccesses,
+		},
+		{
+			name:      "restore of the own namespace into another namespace",
+			operation: admissionv1.Create,
+			spec:      spec([]string{"team"}, []string{"ConfigMap"}, "payments"),
+			accesses:  teamAccesses,
+		},
+		{
+			name:      "restore of every namespace",
+			operation: admissionv1.Create,
+			spec:      spec(nil, []string{"ConfigMap"}, ""),
+			accesses:  teamAccesses,
+		},
+		{
+			name:      "restore of every kind",
+			operation: admissionv1.Create,
+			spec:      spec([]string{"team"}, nil, ""),
+			accesses:  teamAccesses,
+		},
+		{
+			name:            "restore of every kind by an admin of the namespace",
+			operation:       admissionv1.Create,
+			spec:            spec([]string{"team"}, nil, ""),
+			accesses:        []string{"get/team/*", "create/team/*", "update/team/*"},
+			expectedAllowed: true,
+			expectedReviews: 3,
+		},
+		{
+			name:            "update not changing the spec",
+			operation:       admissionv1.Update,
+			oldSpec:         spec([]string{"payments"}, []string{"Secret"}, "team"),
+			spec:            spec([]string{"payments"}, []string{"Secret"}, "team"),
+			accesses:        teamAccesses,
+			expectedAllowed: true,
+		},
+		{
+			name:      "update changing the selector to another namespace",
+			operation: admissionv1.Update,
+			oldSpec:   spec([]string{"team"}, []string{"Secret"}, ""),
+			spec:      spec([]string{"payments"}, []string{"Secret"}, "team"),
+			accesses:  teamAccesses,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			setupFakeClients(t)
+
+			reviews := 0
+			coreClient := globals.Application.KubeRawCoreClient.(*kubernetesfake.Clientset)
+			coreClient.PrependReactor("create", "subjectaccessreviews",
+				func(action k8stesting.Action) (bool, runtime.Object, error) {
+					review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+					attributes := review.Spec.ResourceAttributes
+					reviews++
+
+					review.Status.Allowed = review.Spec.User == "jane" && (slices.Contains(test.accesses,
+						fmt.Sprintf("%s/%s/%s", attributes.Verb, attributes.Namespace, attributes.Resource)) ||
+						slices.Contains(test.accesses, fmt.Sprintf("%s/%s/*", attributes.Verb, attributes.Namespace)))
+					return true, review, nil
+				})
+
+			newRaw := func(spec kuberecoveryv1alpha1.RecoveryRestoreSpec) []byte {
+				raw, err := json.Marshal(&kuberecoveryv1alpha1.RecoveryRestore{
+					TypeMeta:   metav1.TypeMeta{APIVersion: "kuberecovery.freepik.com/v1alpha1", Kind: "RecoveryRestore"},
+					ObjectMeta: metav1.ObjectMeta{Name: "restore-sample"},
+					Spec:       spec,
+				})
+				if err != nil {
+					t.Fatalf("unexpected error encoding the restore: %v", err)
+				}
+				return raw
+			}
+			request := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Operation: test.operation,
+				Object:    runtime.RawExtension{Raw: newRaw(test.spec)},
+				UserInfo:  authenticationv1.UserInfo{Username: "jane"},
+			}}
+			if test.operation == admissionv1.Update {
+				request.OldObject = runtime.RawExtension{Raw: newRaw(test.oldSpec)}
+			}
+
+			response := (&restoreRequesterWebhook{}).Handle(context.Background(), request)
+			if response.Allowed != test.expectedAllowed {
+				t.Errorf("expected allowed %t, got %t: %v", test.expectedAllowed, response.Allowed, response.Result)
+			}
+			if test.expectedAllowed && reviews != test.expectedReviews {
+				t.Errorf("expected %d access reviews, got %d", test.expectedReviews, reviews)
+			}
+		})
+	}
+}
@@ -0,0 +1,591 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/globals"
+	"freepik.com/kuberecovery/internal/storage"
+)
+
+// recoveryCandidate is a RecoveryResource selected to be restored, with the identity of the resource saved on it
+type recoveryCandidate struct {
+	recoveryResource *kuberecoveryv1alpha1.RecoveryResource
+	identity         *unstructured.Unstructured
+	savedAt          time.Time
+}
+
+// defaultRestoreOrder is the order the kinds are restored in, so every resource is created after the ones it
+// depends on. The kinds not listed are restored the last
+var defaultRestoreOrder = []string{
+	"Namespace",
+	"CustomResourceDefinition",
+	"ServiceAccount",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"ConfigMap",
+	"Secret",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"Deployment",
+	"StatefulSet",
+	"DaemonSet",
+	"ReplicaSet",
+	"CronJob",
+	"Job",
+	"Pod",
+	"Service",
+	"Ingress",
+}
+
+// sortByRestoreOrder sorts the candidates by the restore order of their kinds: first the kinds of the order,
+// then the ones of the default order and the rest. The candidates of the same kind keep their relative order
+func sortByRestoreOrder(candidates []recoveryCandidate, order []string) {
+	rank := func(kind string) int {
+		if index := slices.Index(order, kind); index >= 0 {
+			return index
+		}
+		if index := slices.Index(defaultRestoreOrder, kind); index >= 0 {
+			return len(order) + index
+		}
+		return len(order) + len(defaultRestoreOrder)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return rank(candidates[i].identity.GetKind()) < rank(candidates[j].identity.GetKind())
+	})
+}
+
+// isNamespaceObject returns true if the resource is a Namespace
+func isNamespaceObject(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == "" && gvk.Kind == "Namespace"
+}
+
+// loadResourceToRestore returns the resource saved in the RecoveryResource, sanitized and with the restore overrides
+// applied: first the ones in the annotations of the RecoveryResource and then the ones of the restore request, if any.
+// The captured resource, as it was saved, is returned too
+func loadResourceToRestore(ctx context.Context, backend storage.Backend,
+	recoveryResource *kuberecoveryv1alpha1.RecoveryResource,
+	requestOverrides *kuberecoveryv1alpha1.RestoreOverridesT) (resourceToRestore, captured *unstructured.Unstructured,
+	err error) {
+
+	captured, err = loadRecoveryResourcePayload(ctx, backend, recoveryResource)
+	if err != nil {
+		return nil, nil, err
+	}
+	resourceToRestore = captured.DeepCopy()
+	sanitizeResource(resourceToRestore)
+
+	overrides, err := getRecoveryResourceOverrides(recoveryResource)
+	if err != nil {
+		return nil, nil, err
+	}
+	err = applyRestoreOverrides(resourceToRestore, overrides)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if requestOverrides != nil {
+		err = applyRestoreOverrides(resourceToRestore, *requestOverrides)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return resourceToRestore, captured, nil
+}
+
+// restoreRequest are the options of a restore of the resource saved in a RecoveryResource
+type restoreRequest struct {
+	// overrides and conflictStrategy of the request, if any. They take precedence over the ones
+	// set in the annotations of the RecoveryResource
+	overrides        *kuberecoveryv1alpha1.RestoreOverridesT
+	conflictStrategy string
+
+	// defaultStrategy is applied when neither the request nor the RecoveryResource set a conflict strategy
+	defaultStrategy string
+
+	// dryRun validates the restore with the API server without changing the cluster
+	dryRun bool
+
+	// skipUnchanged keeps the live resource untouched when it does not differ from the resource to restore
+	skipUnchanged bool
+
+	// uidMapping maps the UIDs of the resources restored together to their new UIDs.
+	// The ownerReferences of the restored resource are rewired with it
+	uidMapping map[string]string
+}
+
+// restoreRecoveryResource restores the resource saved in the RecoveryResource with the options of the request.
+// It returns the result to record in the status of the RecoveryResource, with the diff against the live resource
+// on dry runs
+func restoreRecoveryResource(ctx context.Context, backend storage.Backend,
+	recoveryResource *kuberecoveryv1alpha1.RecoveryResource,
+	request restoreRequest) (lastRestore *kuberecoveryv1alpha1.LastRestoreT, err error) {
+
+	var resourceToRestore *unstructured.Unstructured
+	var strategy, result, convertedFrom string
+	var diff []kuberecoveryv1alpha1.FieldDiffT
+	var steps []kuberecoveryv1alpha1.RestoreStepT
+
+	// The result is always reported, also when the restore fails before reaching the API server
+	defer func() {
+		lastRestore = newLastRestore(resourceToRestore, strategy, result, err)
+		lastRestore.DryRun = request.dryRun
+		lastRestore.Diff = diff
+		lastRestore.ConvertedFrom = convertedFrom
+		lastRestore.Steps = steps
+	}()
+
+	strategy, err = getConflictStrategy(recoveryResource, request.conflictStrategy, request.defaultStrategy)
+	if err != nil {
+		return lastRestore, err
+	}
+
+	var captured *unstructured.Unstructured
+	resourceToRestore, captured, err = loadResourceToRestore(ctx, backend, recoveryResource, request.overrides)
+	if err != nil {
+		return lastRestore, err
+	}
+	rewireOwnerReferences(resourceToRestore, request.uidMapping)
+	removeStaleOwnerReferences(ctx, resourceToRestore, request.uidMapping)
+
+	// The captured apiVersion may not be served anymore after an upgrade of the cluster
+	convertedFrom, err = convertToServedVersion(ctx, resourceToRestore)
+	if err != nil {
+		return lastRestore, err
+	}
+	if convertedFrom != "" {
+		log.FromContext(ctx).Info(fmt.Sprintf(resourceConvertedMessage, recoveryResource.Name,
+			convertedFrom, resourceToRestore.GetAPIVersion()))
+	}
+
+	// The live resource is compared once the resource to restore is sanitized and converted, before
+	// the restore handlers change anything in the cluster
+	if request.skipUnchanged {
+		var liveResource *unstructured.Unstructured
+		liveResource, err = getLiveResource(ctx, resourceToRestore)
+		if err != nil {
+			return lastRestore, err
+		}
+		if liveResource != nil && len(diffResources(liveResource, resourceToRestore)) == 0 {
+			result = restoreResultUnchanged
+			resourceToRestore.SetUID(liveResource.GetUID())
+			return lastRestore, nil
+		}
+	}
+
+	// Kinds bound to other resources of the cluster look at the captured resource, as the binding is sanitized
+	steps, err = prepareRestore(ctx, captured, resourceToRestore)
+	if err != nil {
+		return lastRestore, err
+	}
+
+	// The diff is computed before the restore, as a rename changes the name of the resource to restore
+	if request.dryRun {
+		diff, err = diffLiveResource(ctx, resourceToRestore)
+		if err != nil {
+			return lastRestore, err
+		}
+	}
+
+	result, err = restoreResource(ctx, resourceToRestore, strategy, request.dryRun)
+	if err != nil {
+		return lastRestore, err
+	}
+
+	completedSteps, err := completeRestore(ctx, resourceToRestore, result, request.dryRun)
+	steps = append(steps, completedSteps...)
+	return lastRestore, err
+}
+
+// restoreCandidates restores the candidates in order with the restore function, recording every item in the status
+// with recordStatus before and after restoring it. A restore interrupted halfway, or whose status could not be updated,
+// carries on from the first candidate not recorded yet. The items interrupted before their result was recorded are
+// failed instead of restored again, as they may have changed the cluster already
+func restoreCandidates(candidates []recoveryCandidate, items *[]kuberecoveryv1alpha1.RestoredItemT,
+	recordStatus func() error, restore func(candidate recoveryCandidate) kuberecoveryv1alpha1.RestoredItemT) error {
+
+	for _, candidate := range candidates {
+		index := slices.IndexFunc(*items, func(item kuberecoveryv1alpha1.RestoredItemT) bool {
+			return item.RecoveryResource == candidate.recoveryResource.Name
+		})
+		if index >= 0 {
+			if (*items)[index].Result == restoreResultInProgress {
+				(*items)[index].Result = restoreResultFailed
+				(*items)[index].Message = restoreInterruptedMessage
+			}
+			continue
+		}
+
+		item := newRestoredItem(candidate.recoveryResource, candidate.identity)
+		item.Result = restoreResultInProgress
+		*items = append(*items, item)
+		err := recordStatus()
+		if err != nil {
+			*items = (*items)[:len(*items)-1]
+			return err
+		}
+
+		(*items)[len(*items)-1] = restore(candidate)
+		err = recordStatus()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// countFailedItems returns the number of items whose restore failed
+func countFailedItems(items []kuberecoveryv1alpha1.RestoredItemT) (failed int) {
+	for _, item := range items {
+		if item.Result == restoreResultFailed {
+			failed++
+		}
+	}
+	return failed
+}
+
+// getConflictStrategy returns the strategy applied when the resource saved in the RecoveryResource already exists:
+// the one of the restore request, if any, then the one in the annotations of the RecoveryResource and the default one
+func getConflictStrategy(recoveryResource *kuberecoveryv1alpha1.RecoveryResource,
+	requestStrategy, defaultStrategy string) (string, error) {
+
+	if requestStrategy != "" {
+		return requestStrategy, nil
+	}
+
+	strategy, exists := recoveryResource.GetAnnotations()[recoveryResourceRestoreConflictStrategyAnnotation]
+	if !exists {
+		return defaultStrategy, nil
+	}
+
+	switch strategy {
+	case conflictStrategyFail, conflictStrategySkip, conflictStrategyReplace, conflictStrategyApply, conflictStrategyRename:
+		return strategy, nil
+	}
+	return "", fmt.Errorf(invalidConflictStrategyError, strategy, recoveryResource.Name)
+}
+
+// restoreResource creates the saved resource in the cluster. When the resource already exists, the conflict
+// strategy decides what to do: fail, skip it, replace or apply the saved resource over the live one,
+// or create it again with a generated name. On rename, the name of the saved resource is updated to the new one,
+// and on success its UID is set to the one of the resource in the cluster.
+// On dry runs, every request is validated by the API server without persisting it
+func restoreResource(ctx context.Context, resourceToRestore *unstructured.Unstructured,
+	strategy string, dryRun bool) (result string, err error) {
+
+	dynamicClient, err := getResourceClient(resourceToRestore)
+	if err != nil {
+		return result, err
+	}
+
+	var dryRunOption []string
+	if dryRun {
+		dryRunOption = []string{metav1.DryRunAll}
+	}
+
+	restored, err := dynamicClient.Create(ctx, resourceToRestore, metav1.CreateOptions{DryRun: dryRunOption})
+	switch {
+	case err == nil:
+		result = restoreResultCreated
+
+	case !apierrors.IsAlreadyExists(err):
+		return result, fmt.Errorf(createResourceError, resourceToRestore.GetName(), err)
+
+	case strategy == conflictStrategySkip:
+		result = restoreResultSkipped
+		restored, err = dynamicClient.Get(ctx, resourceToRestore.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return "", fmt.Errorf(getLiveResourceError, resourceToRestore.GetName(), err)
+		}
+
+	case strategy == conflictStrategyReplace:
+		result = restoreResultReplaced
+		restored, err = replaceResource(ctx, dynamicClient, resourceToRestore, dryRunOption)
+		if err != nil {
+			return "", err
+		}
+		if restored == nil {
+			// The live resource was deleted in the meantime, so it can be created again
+			result = restoreResultCreated
+			restored, err = dynamicClient.Create(ctx, resourceToRestore, metav1.CreateOptions{DryRun: dryRunOption})
+			if err != nil {
+				return "", fmt.Errorf(createResourceError, resourceToRestore.GetName(), err)
+			}
+		}
+
+	case strategy == conflictStrategyApply:
+		result = restoreResultApplied
+		restored, err = applyResource(ctx, dynamicClient, resourceToRestore, dryRunOption)
+		if err != nil {
+			return "", err
+		}
+
+	case strategy == conflictStrategyRename:
+		result = restoreResultRenamed
+		resourceToRestore.SetGenerateName(fmt.Sprintf(restoreRenameNameFormat, resourceToRestore.GetName()))
+		resourceToRestore.SetName("")
+		restored, err = dynamicClient.Create(ctx, resourceToRestore, metav1.CreateOptions{DryRun: dryRunOption})
+		if err != nil {
+			return "", fmt.Errorf(createResourceError, resourceToRestore.GetGenerateName(), err)
+		}
+		resourceToRestore.SetName(restored.GetName())
+
+	default:
+		return result, fmt.Errorf(resourceAlreadyExistsError, resourceToRestore.GetName(), strategy)
+	}
+
+	resourceToRestore.SetUID(restored.GetUID())
+	return result, nil
+}
+
+// replaceResource replaces the live resource with the saved one and returns the replaced resource.
+// It returns nil when the resource does not exist in the cluster, so it has to be created instead
+func replaceResource(ctx context.Context, dynamicClient dynamic.ResourceInterface,
+	resourceToRestore *unstructured.Unstructured, dryRunOption []string) (*unstructured.Unstructured, error) {
+
+	liveResource, err := dynamicClient.Get(ctx, resourceToRestore.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf(getLiveResourceError, resourceToRestore.GetName(), err)
+	}
+
+	// The fields removed by the sanitizers that can not be changed are kept as they are in the live resource
+	preserveLiveFields(resourceToRestore, liveResource)
+	resourceToRestore.SetResourceVersion(liveResource.GetResourceVersion())
+	replaced, err := dynamicClient.Update(ctx, resourceToRestore, metav1.UpdateOptions{DryRun: dryRunOption})
+	if err != nil {
+		return nil, fmt.Errorf(updateResourceError, resourceToRestore.GetName(), err)
+	}
+
+	return replaced, nil
+}
+
+// applyResource applies the saved resource over the live one and returns the applied resource.
+// The fields removed by the sanitizers that can not be changed are kept as they are in the live resource
+func applyResource(ctx context.Context, dynamicClient dynamic.ResourceInterface,
+	resourceToRestore *unstructured.Unstructured, dryRunOption []string) (*unstructured.Unstructured, error) {
+
+	liveResource, err := dynamicClient.Get(ctx, resourceToRestore.GetName(), metav1.GetOptions{})
+	switch {
+	case err == nil:
+		preserveLiveFields(resourceToRestore, liveResource)
+	case !apierrors.IsNotFound(err):
+		return nil, fmt.Errorf(getLiveResourceError, resourceToRestore.GetName(), err)
+	}
+
+	applied, err := dynamicClient.Apply(ctx, resourceToRestore.GetName(), resourceToRestore, metav1.ApplyOptions{
+		FieldManager: restoreFieldManager,
+		Force:        true,
+		DryRun:       dryRunOption,
+	})
+	if err != nil {
+		return nil, fmt.Errorf(applyResourceError, resourceToRestore.GetName(), err)
+	}
+
+	return applied, nil
+}
+
+// sortByOwnership moves the candidates after their owners when they are restored together, so the owners are
+// restored first and the ownerReferences of the candidates can be rewired to their new UIDs.
+// Otherwise, the candidates keep their order
+func sortByOwnership(candidates []recoveryCandidate) {
+
+	// Position of every candidate by the UID of the resource saved on it
+	positions := make(map[types.UID]int, len(candidates))
+	for i, candidate := range candidates {
+		if uid := candidate.recoveryResource.GetLabels()[recoveryResourceSourceUIDLabel]; uid != "" {
+			positions[types.UID(uid)] = i
+		}
+	}
+
+	placed := make([]bool, len(candidates))
+	ownersPlaced := func(i int) bool {
+		for _, ownerReference := range candidates[i].identity.GetOwnerReferences() {
+			position, exists := positions[ownerReference.UID]
+			if exists && position != i && !placed[position] {
+				return false
+			}
+		}
+		return true
+	}
+
+	// Place every time the first candidate whose owners are already placed. On ownership cycles,
+	// the first candidate not placed yet is placed anyway
+	sorted := make([]recoveryCandidate, 0, len(candidates))
+	for len(sorted) < len(candidates) {
+		next := -1
+		for i := range candidates {
+			if placed[i] {
+				continue
+			}
+			if next < 0 {
+				next = i
+			}
+			if ownersPlaced(i) {
+				next = i
+				break
+			}
+		}
+		placed[next] = true
+		sorted = append(sorted, candidates[next])
+	}
+
+	copy(candidates, sorted)
+}
+
+// rewireOwnerReferences points the ownerReferences of the resource to the new UIDs of the owners restored with it
+func rewireOwnerReferences(obj *unstructured.Unstructured, uidMapping map[string]string) {
+	ownerReferences := obj.GetOwnerReferences()
+	if len(ownerReferences) == 0 || len(uidMapping) == 0 {
+		return
+	}
+
+	for i := range ownerReferences {
+		if newUID, exists := uidMapping[string(ownerReferences[i].UID)]; exists {
+			ownerReferences[i].UID = types.UID(newUID)
+		}
+	}
+	obj.SetOwnerReferences(ownerReferences)
+}
+
+// recordUIDMapping adds the UID of the restored resource to the mapping, keyed by the UID of the captured one.
+// Dry runs and failed restores do not create resources, so they are not recorded
+func recordUIDMapping(uidMapping map[string]string, recoveryResource *kuberecoveryv1alpha1.RecoveryResource,
+	lastRestore *kuberecoveryv1alpha1.LastRestoreT) {
+
+	sourceUID := recoveryResource.GetLabels()[recoveryResourceSourceUIDLabel]
+	if sourceUID == "" || lastRestore.UID == "" || lastRestore.DryRun || lastRestore.Result == restoreResultFailed {
+		return
+	}
+	uidMapping[sourceUID] = lastRestore.UID
+}
+
+// newLastRestore returns the status reporting the restore of the resource saved in a RecoveryResource
+func newLastRestore(resourceToRestore *unstructured.Unstructured, strategy, result string,
+	restoreErr error) *kuberecoveryv1alpha1.LastRestoreT {
+
+	lastRestore := &kuberecoveryv1alpha1.LastRestoreT{
+		Time:             metav1.Now(),
+		ConflictStrategy: strategy,
+		Result:           result,
+	}
+	if resourceToRestore != nil {
+		lastRestore.APIVersion = resourceToRestore.GetAPIVersion()
+		lastRestore.Kind = resourceToRestore.GetKind()
+		lastRestore.Namespace = resourceToRestore.GetNamespace()
+		lastRestore.Name = resourceToRestore.GetName()
+		lastRestore.UID = string(resourceToRestore.GetUID())
+	}
+	if result == restoreResultRenamed {
+		lastRestore.Message = fmt.Sprintf(resourceRenamedMessage, lastRestore.Name)
+	}
+	if restoreErr != nil {
+		lastRestore.Result = restoreResultFailed
+		lastRestore.Message = restoreErr.Error()
+	}
+
+	return lastRestore
+}
+
+// setLastRestore sets the last restore in the status of the RecoveryResource, as the last dry run on dry runs.
+// The restore it replaces is kept in the restore history
+func setLastRestore(recoveryResource *kuberecoveryv1alpha1.RecoveryResource,
+	lastRestore *kuberecoveryv1alpha1.LastRestoreT) {
+
+	if lastRestore == nil {
+		return
+	}
+	if lastRestore.DryRun {
+		recoveryResource.Status.LastDryRun = lastRestore
+		return
+	}
+
+	status := &recoveryResource.Status
+	if status.LastRestore != nil {
+		status.RestoreHistory = append(status.RestoreHistory, *status.LastRestore)
+		if len(status.RestoreHistory) > maxRestoreHistory {
+			status.RestoreHistory = status.RestoreHistory[len(status.RestoreHistory)-maxRestoreHistory:]
+		}
+	}
+	status.LastRestore = lastRestore
+}
+
+// updateLastRestore records the last restore in the status of the RecoveryResource.
+// It is used by the restores requested from other resources, so a failure is just logged
+func updateLastRestore(ctx context.Context, c client.Client, recoveryResource *kuberecoveryv1alpha1.RecoveryResource,
+	lastRestore *kuberecoveryv1alpha1.LastRestoreT) {
+
+	logger := log.FromContext(ctx)
+
+	setLastRestore(recoveryResource, lastRestore)
+	err := c.Status().Update(ctx, recoveryResource)
+	if err != nil {
+		logger.Info(fmt.Sprintf(updateLastRestoreError, recoveryResource.Name, err))
+	}
+}
+
+// getResourceClient returns the dynamic client for the resource, for namespaced and cluster-scoped resources
+func getResourceClient(obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+
+	// Create the GVR for the resource
+	gvk := obj.GroupVersionKind()
+	res, err := getResourceFromKind(gvk.Group, gvk.Version, gvk.Kind)
+	if err != nil {
+		return nil, fmt.Errorf(getResourceFromKindError, err)
+	}
+	gvr := gvk.GroupVersion().WithResource(res)
+
+	if obj.GetNamespace() != "" {
+		return globals.Application.KubeRawClient.Resource(gvr).Namespace(obj.GetNamespace()), nil
+	}
+	return globals.Application.KubeRawClient.Resource(gvr), nil
+}
+
+// newRestoredItem returns the status item reporting the restore of the resource saved in the RecoveryResource
+func newRestoredItem(recoveryResource *kuberecoveryv1alpha1.RecoveryResource,
+	identity *unstructured.Unstructured) kuberecoveryv1alpha1.RestoredItemT {
+
+	return kuberecoveryv1alpha1.RestoredItemT{
+		RecoveryResource: recoveryResource.Name,
+		APIVersion:       identity.GetAPIVersion(),
+		Kind:             identity.GetKind(),
+		Namespace:        identity.GetNamespace(),
+		Name:             identity.GetName(),
+	}
+}
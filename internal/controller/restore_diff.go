@@ -0,0 +1,187 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+// paths of the fields of a Secret holding its data, redacted in the diffs
+var secretDataPaths = []string{
+	"/data",
+	"/stringData",
+	"/metadata/annotations/kubectl.kubernetes.io~1last-applied-configuration",
+}
+
+// diffLiveResource returns the fields that differ between the resource to restore and the live resource with
+// the same identity. It returns no diff when the resource does not exist in the cluster
+func diffLiveResource(ctx context.Context,
+	resourceToRestore *unstructured.Unstructured) ([]kuberecoveryv1alpha1.FieldDiffT, error) {
+
+	liveResource, err := getLiveResource(ctx, resourceToRestore)
+	if err != nil || liveResource == nil {
+		return nil, err
+	}
+	return diffResources(liveResource, resourceToRestore), nil
+}
+
+// getLiveResource returns the live resource with the identity of the resource to restore,
+// or nil when it does not exist in the cluster
+func getLiveResource(ctx context.Context,
+	resourceToRestore *unstructured.Unstructured) (*unstructured.Unstructured, error) {
+
+	dynamicClient, err := getResourceClient(resourceToRestore)
+	if err != nil {
+		return nil, err
+	}
+
+	liveResource, err := dynamicClient.Get(ctx, resourceToRestore.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf(getLiveResourceError, resourceToRestore.GetName(), err)
+	}
+	return liveResource, nil
+}
+
+// diffResources returns the fields that differ between the live resource and the resource to restore over it,
+// ignoring the status and the metadata populated by the API server on both of them
+func diffResources(liveResource,
+	resourceToRestore *unstructured.Unstructured) []kuberecoveryv1alpha1.FieldDiffT {
+
+	normalize := func(obj *unstructured.Unstructured) map[string]interface{} {
+		normalized := obj.DeepCopy().Object
+		removeServerPopulatedFields(normalized)
+		return normalized
+	}
+
+	// The restore keeps the fields of the live resource that can not be changed
+	restored := resourceToRestore.DeepCopy()
+	preserveLiveFields(restored, liveResource)
+
+	diff := diffFields("", normalize(liveResource), normalize(restored))
+	if isSecret(resourceToRestore) {
+		redactSecretDiff(diff)
+	}
+	sort.Slice(diff, func(i, j int) bool {
+		return diff[i].Path < diff[j].Path
+	})
+
+	return diff
+}
+
+// diffFields returns the fields that differ between the live and the saved values under the path.
+// Objects and lists with the same length are compared field by field, anything else as a whole
+func diffFields(path string, live, saved interface{}) (diff []kuberecoveryv1alpha1.FieldDiffT) {
+
+	if reflect.DeepEqual(live, saved) {
+		return nil
+	}
+
+	liveMap, liveIsMap := live.(map[string]interface{})
+	savedMap, savedIsMap := saved.(map[string]interface{})
+	if liveIsMap && savedIsMap {
+		keys := make(map[string]bool, len(liveMap)+len(savedMap))
+		for key := range liveMap {
+			keys[key] = true
+		}
+		for key := range savedMap {
+			keys[key] = true
+		}
+		for key := range keys {
+			diff = append(diff, diffFields(path+"/"+escapeJSONPointer(key), liveMap[key], savedMap[key])...)
+		}
+		return diff
+	}
+
+	liveList, liveIsList := live.([]interface{})
+	savedList, savedIsList := saved.([]interface{})
+	if liveIsList && savedIsList && len(liveList) == len(savedList) {
+		for i := range liveList {
+			diff = append(diff, diffFields(fmt.Sprintf("%s/%d", path, i), liveList[i], savedList[i])...)
+		}
+		return diff
+	}
+
+	return []kuberecoveryv1alpha1.FieldDiffT{{
+		Path:  path,
+		Live:  encodeDiffValue(live),
+		Saved: encodeDiffValue(saved),
+	}}
+}
+
+// isSecret returns true if the resource is a core Secret
+func isSecret(obj *unstructured.Unstructured) bool {
+	return obj.GroupVersionKind().Group == "" && obj.GetKind() == "Secret"
+}
+
+// redactSecretDiff hides the values of the fields of a Secret holding its data, so they are never copied to the status
+// of the RecoveryResources and RecoveryRestores. Their paths are still reported. The last applied configuration is
+// redacted too, as it is a copy of the Secret
+func redactSecretDiff(diff []kuberecoveryv1alpha1.FieldDiffT) {
+	for i := range diff {
+		if !isSecretDataPath(diff[i].Path) {
+			continue
+		}
+		if diff[i].Live != "" {
+			diff[i].Live = redactedDiffValue
+		}
+		if diff[i].Saved != "" {
+			diff[i].Saved = redactedDiffValue
+		}
+	}
+}
+
+// isSecretDataPath returns true if the path is, or is under, a field of a Secret holding its data
+func isSecretDataPath(path string) bool {
+	for _, dataPath := range secretDataPaths {
+		if path == dataPath || strings.HasPrefix(path, dataPath+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// escapeJSONPointer escapes the key to be used as a token of a JSON pointer (RFC 6901)
+func escapeJSONPointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+// encodeDiffValue returns the value encoded as JSON, or an empty string when it is not set
+func encodeDiffValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return string(encoded)
+}
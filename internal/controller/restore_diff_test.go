@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+func TestDiffFields(t *testing.T) {
+	tests := []struct {
+		name     string
+		live     interface{}
+		saved    interface{}
+		expected []kuberecoveryv1alpha1.FieldDiffT
+	}{
+		{
+			name:  "equal objects",
+			live:  map[string]interface{}{"replicas": int64(3), "paused": false},
+			saved: map[string]interface{}{"replicas": int64(3), "paused": false},
+		},
+		{
+			name:  "changed, added and removed fields",
+			live:  map[string]interface{}{"replicas": int64(3), "paused": true},
+			saved: map[string]interface{}{"replicas": int64(1), "minReadySeconds": int64(10)},
+			expected: []kuberecoveryv1alpha1.FieldDiffT{
+				{Path: "/spec/minReadySeconds", Saved: "10"},
+				{Path: "/spec/paused", Live: "true"},
+				{Path: "/spec/replicas", Live: "3", Saved: "1"},
+			},
+		},
+		{
+			name: "nested fields with escaped keys",
+			live: map[string]interface{}{
+				"selector": map[string]interface{}{"app.kubernetes.io/name": "web", "tier": "frontend"},
+			},
+			saved: map[string]interface{}{
+				"selector": map[string]interface{}{"app.kubernetes.io/name": "api", "tier": "frontend"},
+			},
+			expected: []kuberecoveryv1alpha1.FieldDiffT{
+				{Path: "/spec/selector/app.kubernetes.io~1name", Live: `"web"`, Saved: `"api"`},
+			},
+		},
+		{
+			name: "lists with the same length compared item by item",
+			live: map[string]interface{}{
+				"ports": []interface{}{
+					map[string]interface{}{"port": int64(80)},
+					map[string]interface{}{"port": int64(443)},
+				},
+			},
+			saved: map[string]interface{}{
+				"ports": []interface{}{
+					map[string]interface{}{"port": int64(80)},
+					map[string]interface{}{"port": int64(8443)},
+				},
+			},
+			expected: []kuberecoveryv1alpha1.FieldDiffT{
+				{Path: "/spec/ports/1/port", Live: "443", Saved: "8443"},
+			},
+		},
+		{
+			name:  "lists with different length compared as a whole",
+			live:  map[string]interface{}{"finalizers": []interface{}{"a"}},
+			saved: map[string]interface{}{"finalizers": []interface{}{"a", "b"}},
+			expected: []kuberecoveryv1alpha1.FieldDiffT{
+				{Path: "/spec/finalizers", Live: `["a"]`, Saved: `["a","b"]`},
+			},
+		},
+		{
+			name:  "values of different types",
+			live:  map[string]interface{}{"data": "raw"},
+			saved: map[string]interface{}{"data": map[string]interface{}{"key": "value"}},
+			expected: []kuberecoveryv1alpha1.FieldDiffT{
+				{Path: "/spec/data", Live: `"raw"`, Saved: `{"key":"value"}`},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			diff := diffFields("/spec", test.live, test.saved)
+			sort.Slice(diff, func(i, j int) bool {
+				return diff[i].Path < diff[j].Path
+			})
+
+			if !reflect.DeepEqual(diff, test.expected) {
+				t.Errorf("expected diff %+v, got %+v", test.expected, diff)
+			}
+		})
+	}
+}
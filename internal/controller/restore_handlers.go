@@ -0,0 +1,201 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+	"freepik.com/kuberecovery/internal/globals"
+)
+
+// restoreHandlerT restores the resources of a kind that must be bound to other resources of the cluster,
+// reporting every step done in the status of the RecoveryResource
+type restoreHandlerT struct {
+	// prepare changes the resource to restore before creating it, looking at the captured one
+	prepare func(ctx context.Context, captured, resourceToRestore *unstructured.Unstructured) (
+		[]kuberecoveryv1alpha1.RestoreStepT, error)
+
+	// complete binds the resource once it was created in the cluster
+	complete func(ctx context.Context, restored *unstructured.Unstructured, dryRun bool) (
+		[]kuberecoveryv1alpha1.RestoreStepT, error)
+}
+
+// restoreHandlers are the handlers of the kinds that need more than being created to be restored
+var restoreHandlers = map[schema.GroupKind]restoreHandlerT{
+	{Group: "", Kind: "PersistentVolumeClaim"}: {
+		prepare:  preparePersistentVolumeClaim,
+		complete: rebindPersistentVolume,
+	},
+}
+
+// results of the restore after which the handlers complete it, as the resource was created again
+var resultsCompletedByHandlers = []string{
+	restoreResultCreated,
+	restoreResultReplaced,
+	restoreResultRenamed,
+}
+
+// prepareRestore runs the handler of the kind of the resource before restoring it, if any
+func prepareRestore(ctx context.Context,
+	captured, resourceToRestore *unstructured.Unstructured) ([]kuberecoveryv1alpha1.RestoreStepT, error) {
+
+	handler, exists := restoreHandlers[resourceToRestore.GroupVersionKind().GroupKind()]
+	if !exists {
+		return nil, nil
+	}
+	return handler.prepare(ctx, captured, resourceToRestore)
+}
+
+// completeRestore runs the handler of the kind of the resource after restoring it, if any.
+// Resources that already existed and were kept are not completed
+func completeRestore(ctx context.Context, restored *unstructured.Unstructured, result string,
+	dryRun bool) ([]kuberecoveryv1alpha1.RestoreStepT, error) {
+
+	handler, exists := restoreHandlers[restored.GroupVersionKind().GroupKind()]
+	if !exists || !slices.Contains(resultsCompletedByHandlers, result) {
+		return nil, nil
+	}
+	return handler.complete(ctx, restored, dryRun)
+}
+
+// preparePersistentVolumeClaim looks for the volume the captured PersistentVolumeClaim was bound to. When it was
+// retained and released, the claim is restored with its volumeName so it is bound to it again instead of
+// provisioning a new empty volume
+func preparePersistentVolumeClaim(ctx context.Context,
+	captured, resourceToRestore *unstructured.Unstructured) ([]kuberecoveryv1alpha1.RestoreStepT, error) {
+
+	volumeName, _, _ := unstructured.NestedString(captured.Object, "spec", "volumeName")
+	if volumeName == "" {
+		return nil, nil
+	}
+
+	step := kuberecoveryv1alpha1.RestoreStepT{Name: restoreStepFindVolume, Result: restoreResultSkipped}
+
+	volume, err := globals.Application.KubeRawCoreClient.CoreV1().PersistentVolumes().Get(ctx,
+		volumeName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			step.Message = fmt.Sprintf(volumeNotFoundMessage, volumeName)
+			return []kuberecoveryv1alpha1.RestoreStepT{step}, nil
+		}
+
+		// The claim is not restored unbound, as a new empty volume would be provisioned for it
+		step.Result = restoreResultFailed
+		step.Message = fmt.Sprintf(getVolumeError, volumeName, err)
+		return []kuberecoveryv1alpha1.RestoreStepT{step}, fmt.Errorf(getVolumeError, volumeName, err)
+	}
+
+	// Only volumes released by the deleted claim, or available and not reserved for another claim, are bound again
+	claimRef := volume.Spec.ClaimRef
+	switch {
+	case volume.Status.Phase == corev1.VolumeBound && claimRef != nil:
+		step.Message = fmt.Sprintf(volumeBoundMessage, volumeName, claimRef.Namespace, claimRef.Name)
+		return []kuberecoveryv1alpha1.RestoreStepT{step}, nil
+
+	case volume.Status.Phase == corev1.VolumeAvailable && claimRef != nil &&
+		(claimRef.Namespace != resourceToRestore.GetNamespace() || claimRef.Name != resourceToRestore.GetName()):
+		step.Message = fmt.Sprintf(volumeBoundMessage, volumeName, claimRef.Namespace, claimRef.Name)
+		return []kuberecoveryv1alpha1.RestoreStepT{step}, nil
+
+	case volume.Status.Phase == corev1.VolumeReleased &&
+		volume.Spec.PersistentVolumeReclaimPolicy == corev1.PersistentVolumeReclaimRetain:
+		// Retained after the deletion of the claim, with its stale claimRef replaced once the claim is restored
+
+	case volume.Status.Phase != corev1.VolumeAvailable:
+		step.Message = fmt.Sprintf(volumeNotReclaimableMessage, volumeName, volume.Status.Phase,
+			volume.Spec.PersistentVolumeReclaimPolicy)
+		return []kuberecoveryv1alpha1.RestoreStepT{step}, nil
+	}
+
+	err = unstructured.SetNestedField(resourceToRestore.Object, volumeName, "spec", "volumeName")
+	if err != nil {
+		return nil, err
+	}
+
+	step.Result = restoreResultSucceeded
+	step.Message = fmt.Sprintf(volumeFoundMessage, volumeName, volume.Status.Phase,
+		volume.Spec.PersistentVolumeReclaimPolicy)
+	return []kuberecoveryv1alpha1.RestoreStepT{step}, nil
+}
+
+// rebindPersistentVolume points the claimRef of the volume the PersistentVolumeClaim was restored with to the
+// restored claim, replacing the stale one of the deleted claim. The PersistentVolume controller then completes
+// the binding of both of them
+func rebindPersistentVolume(ctx context.Context, restored *unstructured.Unstructured,
+	dryRun bool) ([]kuberecoveryv1alpha1.RestoreStepT, error) {
+
+	volumeName, _, _ := unstructured.NestedString(restored.Object, "spec", "volumeName")
+	if volumeName == "" {
+		return nil, nil
+	}
+
+	steps := []kuberecoveryv1alpha1.RestoreStepT{{
+		Name:    restoreStepBindClaim,
+		Result:  restoreResultSucceeded,
+		Message: fmt.Sprintf(claimBoundMessage, volumeName),
+	}}
+	step := kuberecoveryv1alpha1.RestoreStepT{Name: restoreStepRebindVolume, Result: restoreResultSucceeded}
+
+	// The resourceVersion of the stale claimRef is removed, and the UID of the restored claim is set
+	// when it is known, as dry runs may not return it
+	var claimUID interface{}
+	if restored.GetUID() != "" {
+		claimUID = string(restored.GetUID())
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"claimRef": map[string]interface{}{
+				"apiVersion":      "v1",
+				"kind":            "PersistentVolumeClaim",
+				"namespace":       restored.GetNamespace(),
+				"name":            restored.GetName(),
+				"uid":             claimUID,
+				"resourceVersion": nil,
+			},
+		},
+	})
+	if err != nil {
+		return steps, err
+	}
+
+	var dryRunOption []string
+	if dryRun {
+		dryRunOption = []string{metav1.DryRunAll}
+	}
+	_, err = globals.Application.KubeRawCoreClient.CoreV1().PersistentVolumes().Patch(ctx, volumeName,
+		types.MergePatchType, patch, metav1.PatchOptions{DryRun: dryRunOption})
+	if err != nil {
+		err = fmt.Errorf(rebindVolumeError, volumeName, restored.GetNamespace(), restored.GetName(), err)
+		step.Result = restoreResultFailed
+		step.Message = err.Error()
+		return append(steps, step), err
+	}
+
+	step.Message = fmt.Sprintf(volumeReboundMessage, volumeName, restored.GetNamespace(), restored.GetName())
+	return append(steps, step), nil
+}
@@ -0,0 +1,183 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+// getRecoveryResourceOverrides returns the restore overrides set in the annotations of the RecoveryResource
+func getRecoveryResourceOverrides(
+	resource *kuberecoveryv1alpha1.RecoveryResource) (overrides kuberecoveryv1alpha1.RestoreOverridesT, err error) {
+
+	annotations := resource.GetAnnotations()
+
+	overrides.Namespace = annotations[recoveryResourceRestoreNamespaceAnnotation]
+	overrides.Name = annotations[recoveryResourceRestoreNameAnnotation]
+	overrides.JSONPatch = annotations[recoveryResourceRestoreJSONPatchAnnotation]
+	overrides.StrategicMergePatch = annotations[recoveryResourceRestoreStrategicMergePatchAnnotation]
+
+	if value, exists := annotations[recoveryResourceRestoreLabelsAnnotation]; exists {
+		err = yaml.Unmarshal([]byte(value), &overrides.Labels)
+		if err != nil {
+			return overrides, fmt.Errorf(parseRestoreOverridesError, recoveryResourceRestoreLabelsAnnotation, err)
+		}
+	}
+
+	if value, exists := annotations[recoveryResourceRestoreAnnotationsAnnotation]; exists {
+		err = yaml.Unmarshal([]byte(value), &overrides.Annotations)
+		if err != nil {
+			return overrides, fmt.Errorf(parseRestoreOverridesError, recoveryResourceRestoreAnnotationsAnnotation, err)
+		}
+	}
+
+	return overrides, nil
+}
+
+// applyRestoreOverrides changes the resource to restore with the overrides. The namespace and the name are
+// changed first, then the labels and annotations, and the patches are applied last
+func applyRestoreOverrides(obj *unstructured.Unstructured, overrides kuberecoveryv1alpha1.RestoreOverridesT) error {
+
+	if overrides.Namespace != "" {
+		if obj.GetNamespace() == "" {
+			return fmt.Errorf(namespaceOverrideError, obj.GetKind(), obj.GetName())
+		}
+		obj.SetNamespace(overrides.Namespace)
+	}
+
+	if overrides.Name != "" {
+		obj.SetName(overrides.Name)
+	}
+
+	if len(overrides.Labels) > 0 {
+		obj.SetLabels(mergeStringMap(obj.GetLabels(), overrides.Labels))
+	}
+
+	if len(overrides.Annotations) > 0 {
+		obj.SetAnnotations(mergeStringMap(obj.GetAnnotations(), overrides.Annotations))
+	}
+
+	if overrides.JSONPatch != "" {
+		err := applyJSONPatch(obj, overrides.JSONPatch)
+		if err != nil {
+			return err
+		}
+	}
+
+	if overrides.StrategicMergePatch != "" {
+		err := applyStrategicMergePatch(obj, overrides.StrategicMergePatch)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeStringMap sets the values of the overrides in the original map. Empty values remove the key
+func mergeStringMap(original, overrides map[string]string) map[string]string {
+	merged := make(map[string]string, len(original)+len(overrides))
+	for key, value := range original {
+		merged[key] = value
+	}
+	for key, value := range overrides {
+		if value == "" {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+	return merged
+}
+
+// applyJSONPatch applies the JSON patch (RFC 6902), written in JSON or YAML, to the resource
+func applyJSONPatch(obj *unstructured.Unstructured, patch string) error {
+
+	patchJSON, err := yaml.YAMLToJSON([]byte(patch))
+	if err != nil {
+		return fmt.Errorf(applyRestorePatchError, obj.GetName(), err)
+	}
+
+	decodedPatch, err := jsonpatch.DecodePatch(patchJSON)
+	if err != nil {
+		return fmt.Errorf(applyRestorePatchError, obj.GetName(), err)
+	}
+
+	original, err := json.Marshal(obj.Object)
+	if err != nil {
+		return fmt.Errorf(applyRestorePatchError, obj.GetName(), err)
+	}
+
+	patched, err := decodedPatch.Apply(original)
+	if err != nil {
+		return fmt.Errorf(applyRestorePatchError, obj.GetName(), err)
+	}
+
+	return setUnstructuredContent(obj, patched)
+}
+
+// applyStrategicMergePatch applies the strategic merge patch, written in JSON or YAML, to the resource.
+// Strategic merge patches need the Go type of the resource, so the kinds not registered in the client-go scheme
+// are patched with a JSON merge patch, as kubectl does
+func applyStrategicMergePatch(obj *unstructured.Unstructured, patch string) error {
+
+	patchJSON, err := yaml.YAMLToJSON([]byte(patch))
+	if err != nil {
+		return fmt.Errorf(applyRestorePatchError, obj.GetName(), err)
+	}
+
+	original, err := json.Marshal(obj.Object)
+	if err != nil {
+		return fmt.Errorf(applyRestorePatchError, obj.GetName(), err)
+	}
+
+	var patched []byte
+	dataStruct, err := clientgoscheme.Scheme.New(obj.GroupVersionKind())
+	switch {
+	case runtime.IsNotRegisteredError(err):
+		patched, err = jsonpatch.MergePatch(original, patchJSON)
+	case err == nil:
+		patched, err = strategicpatch.StrategicMergePatch(original, patchJSON, dataStruct)
+	}
+	if err != nil {
+		return fmt.Errorf(applyRestorePatchError, obj.GetName(), err)
+	}
+
+	return setUnstructuredContent(obj, patched)
+}
+
+// setUnstructuredContent replaces the content of the resource with the JSON document
+func setUnstructuredContent(obj *unstructured.Unstructured, content []byte) error {
+	patchedObj := map[string]interface{}{}
+	err := json.Unmarshal(content, &patchedObj)
+	if err != nil {
+		return fmt.Errorf(applyRestorePatchError, obj.GetName(), err)
+	}
+
+	obj.SetUnstructuredContent(patchedObj)
+	return nil
+}
@@ -0,0 +1,298 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+// sanitizeFunc removes from the resource the fields that are populated by the API server or immutable,
+// so they make the restore fail or bind the restored resource to things that do not exist anymore
+type sanitizeFunc func(obj *unstructured.Unstructured)
+
+// labels set by the Job controller to match its Pods, bound to the UID of the Job
+var jobControllerLabels = []string{
+	"controller-uid",
+	"batch.kubernetes.io/controller-uid",
+}
+
+// annotations set by the PersistentVolume controller when a PersistentVolumeClaim is bound
+var persistentVolumeClaimBindAnnotations = []string{
+	"pv.kubernetes.io/bind-completed",
+	"pv.kubernetes.io/bound-by-controller",
+	"volume.kubernetes.io/selected-node",
+}
+
+// restoreSanitizers are the built-in sanitizers of every kind, applied to the resources before restoring them
+var restoreSanitizers = map[schema.GroupKind]sanitizeFunc{
+	{Group: "", Kind: "Service"}:               sanitizeService,
+	{Group: "", Kind: "Pod"}:                   sanitizePod,
+	{Group: "", Kind: "PersistentVolumeClaim"}: sanitizePersistentVolumeClaim,
+	{Group: "batch", Kind: "Job"}:              sanitizeJob,
+}
+
+// preserveFunc copies from the live resource the fields removed by the sanitizer of its kind that can not be changed
+// once the resource is created, so the live resource can be updated with the resource to restore
+type preserveFunc func(obj, liveResource *unstructured.Unstructured)
+
+// restorePreservers are the built-in preservers of every kind with a sanitizer removing immutable fields,
+// applied to the resources restored over the live ones
+var restorePreservers = map[schema.GroupKind]preserveFunc{
+	{Group: "", Kind: "Service"}:               preserveService,
+	{Group: "", Kind: "Pod"}:                   preservePod,
+	{Group: "", Kind: "PersistentVolumeClaim"}: preservePersistentVolumeClaim,
+	{Group: "batch", Kind: "Job"}:              preserveJob,
+}
+
+// sanitizeResource removes the status and the metadata populated by the API server from the resource,
+// and then applies the sanitizer of its kind, if any
+func sanitizeResource(obj *unstructured.Unstructured) {
+	removeServerPopulatedFields(obj.Object)
+
+	if sanitize, exists := restoreSanitizers[obj.GroupVersionKind().GroupKind()]; exists {
+		sanitize(obj)
+	}
+}
+
+// sanitizeService removes the cluster IPs allocated to the Service, unless it is headless
+func sanitizeService(obj *unstructured.Unstructured) {
+	clusterIP, _, _ := unstructured.NestedString(obj.Object, "spec", "clusterIP")
+	if clusterIP == "None" {
+		return
+	}
+	unstructured.RemoveNestedField(obj.Object, "spec", "clusterIP")
+	unstructured.RemoveNestedField(obj.Object, "spec", "clusterIPs")
+}
+
+// sanitizePod removes the node the Pod was scheduled to, so it is scheduled again
+func sanitizePod(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "spec", "nodeName")
+}
+
+// sanitizePersistentVolumeClaim removes the binding of the PersistentVolumeClaim to its volume
+func sanitizePersistentVolumeClaim(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "spec", "volumeName")
+	for _, annotation := range persistentVolumeClaimBindAnnotations {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "annotations", annotation)
+	}
+}
+
+// sanitizeJob removes the selector generated for the Job and the labels bound to its UID,
+// unless the selector was set manually
+func sanitizeJob(obj *unstructured.Unstructured) {
+	manualSelector, _, _ := unstructured.NestedBool(obj.Object, "spec", "manualSelector")
+	if manualSelector {
+		return
+	}
+
+	unstructured.RemoveNestedField(obj.Object, "spec", "selector")
+	for _, label := range jobControllerLabels {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "labels", label)
+		unstructured.RemoveNestedField(obj.Object, "spec", "template", "metadata", "labels", label)
+	}
+}
+
+// preserveLiveFields applies the preserver of the kind of the resource, if any, so the fields removed by its sanitizer
+// keep the values of the live resource the resource is restored over
+func preserveLiveFields(obj, liveResource *unstructured.Unstructured) {
+	if preserve, exists := restorePreservers[obj.GroupVersionKind().GroupKind()]; exists {
+		preserve(obj, liveResource)
+	}
+}
+
+// preserveService keeps the cluster IPs allocated to the live Service
+func preserveService(obj, liveResource *unstructured.Unstructured) {
+	copyLiveField(obj, liveResource, "spec", "clusterIP")
+	copyLiveField(obj, liveResource, "spec", "clusterIPs")
+}
+
+// preservePod keeps the node the live Pod is scheduled to
+func preservePod(obj, liveResource *unstructured.Unstructured) {
+	copyLiveField(obj, liveResource, "spec", "nodeName")
+}
+
+// preservePersistentVolumeClaim keeps the binding of the live PersistentVolumeClaim to its volume
+func preservePersistentVolumeClaim(obj, liveResource *unstructured.Unstructured) {
+	copyLiveField(obj, liveResource, "spec", "volumeName")
+	for _, annotation := range persistentVolumeClaimBindAnnotations {
+		copyLiveField(obj, liveResource, "metadata", "annotations", annotation)
+	}
+}
+
+// preserveJob keeps the selector generated for the live Job and the labels bound to its UID,
+// unless the selector was set manually
+func preserveJob(obj, liveResource *unstructured.Unstructured) {
+	manualSelector, _, _ := unstructured.NestedBool(obj.Object, "spec", "manualSelector")
+	if manualSelector {
+		return
+	}
+
+	copyLiveField(obj, liveResource, "spec", "selector")
+	for _, label := range jobControllerLabels {
+		copyLiveField(obj, liveResource, "metadata", "labels", label)
+		copyLiveField(obj, liveResource, "spec", "template", "metadata", "labels", label)
+	}
+}
+
+// copyLiveField sets the field of the resource to the value it has in the live resource.
+// Fields not set in the live resource are kept untouched
+func copyLiveField(obj, liveResource *unstructured.Unstructured, fields ...string) {
+	value, found, err := unstructured.NestedFieldCopy(liveResource.Object, fields...)
+	if !found || err != nil {
+		return
+	}
+	_ = unstructured.SetNestedField(obj.Object, value, fields...)
+}
+
+// removeStaleOwnerReferences removes the ownerReferences of the resource whose owners are not found in the cluster,
+// so the garbage collector does not delete the resource right after restoring it.
+// The ownerReferences of the owners restored with the resource, rewired or not yet, are kept untouched
+func removeStaleOwnerReferences(ctx context.Context, obj *unstructured.Unstructured, uidMapping map[string]string) {
+	ownerReferences := obj.GetOwnerReferences()
+	if len(ownerReferences) == 0 {
+		return
+	}
+
+	kept := make([]metav1.OwnerReference, 0, len(ownerReferences))
+	for _, ownerReference := range ownerReferences {
+		if isRestoredOwner(uidMapping, ownerReference) || !ownerNotFound(ctx, obj, ownerReference) {
+			kept = append(kept, ownerReference)
+		}
+	}
+	obj.SetOwnerReferences(kept)
+}
+
+// isRestoredOwner checks if the owner of the ownerReference was restored with the resource, by its captured UID
+// or by the UID it was restored with
+func isRestoredOwner(uidMapping map[string]string, ownerReference metav1.OwnerReference) bool {
+	uid := string(ownerReference.UID)
+	if _, exists := uidMapping[uid]; exists {
+		return true
+	}
+	for _, restoredUID := range uidMapping {
+		if restoredUID == uid {
+			return true
+		}
+	}
+	return false
+}
+
+// ownerNotFound checks if the owner of the ownerReference is not found in the cluster. Any other error getting it
+// keeps the ownerReference, as well as an owner with the same name and another UID, as it is unknown if it owns it
+func ownerNotFound(ctx context.Context, obj *unstructured.Unstructured, ownerReference metav1.OwnerReference) bool {
+	owner := &unstructured.Unstructured{}
+	owner.SetAPIVersion(ownerReference.APIVersion)
+	owner.SetKind(ownerReference.Kind)
+
+	// Owners are in the namespace of the resource, unless they are cluster-scoped
+	mapping, err := getRESTMapping(owner.GroupVersionKind().GroupKind(), owner.GroupVersionKind().Version)
+	if err != nil {
+		return false
+	}
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		owner.SetNamespace(obj.GetNamespace())
+	}
+
+	dynamicClient, err := getResourceClient(owner)
+	if err != nil {
+		return false
+	}
+	_, err = dynamicClient.Get(ctx, ownerReference.Name, metav1.GetOptions{})
+	return apierrors.IsNotFound(err)
+}
+
+// applyCaptureSanitizers removes from the resource the fields of the sanitizers of the RecoveryConfig matching it,
+// so they are never stored in the RecoveryResource
+func applyCaptureSanitizers(obj *unstructured.Unstructured, sanitizers []kuberecoveryv1alpha1.SanitizerT) {
+	for _, sanitizer := range sanitizers {
+		if sanitizer.APIVersion != "" && sanitizer.APIVersion != obj.GetAPIVersion() {
+			continue
+		}
+		if !slices.Contains(sanitizer.Kinds, obj.GetKind()) && !slices.Contains(sanitizer.Kinds, "*") {
+			continue
+		}
+
+		for _, fieldPath := range sanitizer.FieldPaths {
+			removeFieldPath(obj.Object, parseJSONPointer(fieldPath))
+		}
+	}
+}
+
+// parseJSONPointer returns the unescaped tokens of the JSON pointer (RFC 6901)
+func parseJSONPointer(pointer string) []string {
+	tokens := strings.Split(strings.TrimPrefix(pointer, "/"), "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+	return tokens
+}
+
+// removeFieldPath removes the field under the path of tokens from the value. Lists are walked by index,
+// and the * token walks every item of a list or every field of an object
+func removeFieldPath(value interface{}, tokens []string) {
+	if len(tokens) == 0 {
+		return
+	}
+	token, rest := tokens[0], tokens[1:]
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		if token == "*" {
+			for key := range typed {
+				if len(rest) == 0 {
+					delete(typed, key)
+					continue
+				}
+				removeFieldPath(typed[key], rest)
+			}
+			return
+		}
+		if len(rest) == 0 {
+			delete(typed, token)
+			return
+		}
+		removeFieldPath(typed[token], rest)
+
+	case []interface{}:
+		// Items of lists are not removed, just fields inside them
+		if len(rest) == 0 {
+			return
+		}
+		if token == "*" {
+			for _, item := range typed {
+				removeFieldPath(item, rest)
+			}
+			return
+		}
+		index, err := strconv.Atoi(token)
+		if err != nil || index < 0 || index >= len(typed) {
+			return
+		}
+		removeFieldPath(typed[index], rest)
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseJSONPointer(t *testing.T) {
+	tests := []struct {
+		name     string
+		pointer  string
+		expected []string
+	}{
+		{name: "single token", pointer: "/status", expected: []string{"status"}},
+		{name: "nested tokens", pointer: "/spec/template/metadata", expected: []string{"spec", "template", "metadata"}},
+		{
+			name:     "escaped tokens",
+			pointer:  "/metadata/annotations/example.com~1tilde~0key",
+			expected: []string{"metadata", "annotations", "example.com/tilde~key"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if tokens := parseJSONPointer(test.pointer); !reflect.DeepEqual(tokens, test.expected) {
+				t.Errorf("expected tokens %v, got %v", test.expected, tokens)
+			}
+		})
+	}
+}
+
+func TestRemoveFieldPath(t *testing.T) {
+	newPod := func() map[string]interface{} {
+		return map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{
+					"example.com/owner": "team",
+					"keep":              "value",
+				},
+			},
+			"spec": map[string]interface{}{
+				"nodeName": "node-1",
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "app:1"},
+					map[string]interface{}{"name": "sidecar", "image": "sidecar:1"},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		pointer  string
+		expected func(pod map[string]interface{})
+	}{
+		{
+			name:    "field of an object",
+			pointer: "/spec/nodeName",
+			expected: func(pod map[string]interface{}) {
+				delete(pod["spec"].(map[string]interface{}), "nodeName")
+			},
+		},
+		{
+			name:    "field with an escaped key",
+			pointer: "/metadata/annotations/example.com~1owner",
+			expected: func(pod map[string]interface{}) {
+				delete(pod["metadata"].(map[string]interface{})["annotations"].(map[string]interface{}), "example.com/owner")
+			},
+		},
+		{
+			name:    "every field of an object",
+			pointer: "/metadata/annotations/*",
+			expected: func(pod map[string]interface{}) {
+				pod["metadata"].(map[string]interface{})["annotations"] = map[string]interface{}{}
+			},
+		},
+		{
+			name:    "field of a list item by index",
+			pointer: "/spec/containers/1/image",
+			expected: func(pod map[string]interface{}) {
+				delete(pod["spec"].(map[string]interface{})["containers"].([]interface{})[1].(map[string]interface{}), "image")
+			},
+		},
+		{
+			name:    "field of every list item",
+			pointer: "/spec/containers/*/image",
+			expected: func(pod map[string]interface{}) {
+				for _, container := range pod["spec"].(map[string]interface{})["containers"].([]interface{}) {
+					delete(container.(map[string]interface{}), "image")
+				}
+			},
+		},
+		{
+			name:     "list items are not removed",
+			pointer:  "/spec/containers/0",
+			expected: func(pod map[string]interface{}) {},
+		},
+		{
+			name:     "index out of the list",
+			pointer:  "/spec/containers/2/image",
+			expected: func(pod map[string]interface{}) {},
+		},
+		{
+			name:     "missing field",
+			pointer:  "/spec/volumes/0/name",
+			expected: func(pod map[string]interface{}) {},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pod := newPod()
+			removeFieldPath(pod, parseJSONPointer(test.pointer))
+
+			expected := newPod()
+			test.expected(expected)
+			if !reflect.DeepEqual(pod, expected) {
+				t.Errorf("expected %v, got %v", expected, pod)
+			}
+		})
+	}
+}
@@ -0,0 +1,463 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+// newRecoveryCandidate returns a candidate saving the resource of the kind with the UID, owned by the resources
+// with the owner UIDs
+func newRecoveryCandidate(name, kind, uid string, ownerUIDs ...string) recoveryCandidate {
+	recoveryResource := &kuberecoveryv1alpha1.RecoveryResource{}
+	recoveryResource.SetName(name)
+	recoveryResource.SetLabels(map[string]string{recoveryResourceSourceUIDLabel: uid})
+
+	identity := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	identity.SetKind(kind)
+	ownerReferences := make([]metav1.OwnerReference, 0, len(ownerUIDs))
+	for _, ownerUID := range ownerUIDs {
+		ownerReferences = append(ownerReferences, metav1.OwnerReference{UID: types.UID(ownerUID)})
+	}
+	identity.SetOwnerReferences(ownerReferences)
+
+	return recoveryCandidate{
+		recoveryResource: recoveryResource,
+		identity:         identity,
+		savedAt:          time.Date(2025, 1, 30, 15, 10, 1, 0, time.UTC),
+	}
+}
+
+// candidateNames returns the names of the RecoveryResources of the candidates, in their order
+func candidateNames(candidates []recoveryCandidate) []string {
+	names := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		names = append(names, candidate.recoveryResource.GetName())
+	}
+	return names
+}
+
+func TestSortByRestoreOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []recoveryCandidate
+		order      []string
+		expected   []string
+	}{
+		{
+			name: "default order",
+			candidates: []recoveryCandidate{
+				newRecoveryCandidate("deployment", "Deployment", "uid-deployment"),
+				newRecoveryCandidate("service", "Service", "uid-service"),
+				newRecoveryCandidate("configmap", "ConfigMap", "uid-configmap"),
+				newRecoveryCandidate("namespace", "Namespace", "uid-namespace"),
+			},
+			expected: []string{"namespace", "configmap", "deployment", "service"},
+		},
+		{
+			name: "kinds out of the default order restored the last",
+			candidates: []recoveryCandidate{
+				newRecoveryCandidate("certificate", "Certificate", "uid-certificate"),
+				newRecoveryCandidate("secret", "Secret", "uid-secret"),
+			},
+			expected: []string{"secret", "certificate"},
+		},
+		{
+			name: "kinds of the order before the default order",
+			candidates: []recoveryCandidate{
+				newRecoveryCandidate("namespace", "Namespace", "uid-namespace"),
+				newRecoveryCandidate("secret", "Secret", "uid-secret"),
+				newRecoveryCandidate("certificate", "Certificate", "uid-certificate"),
+			},
+			order:    []string{"Certificate"},
+			expected: []string{"certificate", "namespace", "secret"},
+		},
+		{
+			name: "candidates of the same kind keep their order",
+			candidates: []recoveryCandidate{
+				newRecoveryCandidate("service-b", "Service", "uid-service-b"),
+				newRecoveryCandidate("configmap", "ConfigMap", "uid-configmap"),
+				newRecoveryCandidate("service-a", "Service", "uid-service-a"),
+			},
+			expected: []string{"configmap", "service-b", "service-a"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sortByRestoreOrder(test.candidates, test.order)
+
+			if sorted := candidateNames(test.candidates); !reflect.DeepEqual(sorted, test.expected) {
+				t.Errorf("expected order %v, got %v", test.expected, sorted)
+			}
+		})
+	}
+}
+
+func TestSortByOwnership(t *testing.T) {
+	tests := []struct {
+		name       string
+		candidates []recoveryCandidate
+		expected   []string
+	}{
+		{
+			name: "candidates without owners keep their order",
+			candidates: []recoveryCandidate{
+				newRecoveryCandidate("configmap", "ConfigMap", "uid-configmap"),
+				newRecoveryCandidate("service", "Service", "uid-service"),
+			},
+			expected: []string{"configmap", "service"},
+		},
+		{
+			name: "owners restored before their dependents",
+			candidates: []recoveryCandidate{
+				newRecoveryCandidate("pod", "Pod", "uid-pod", "uid-replicaset"),
+				newRecoveryCandidate("replicaset", "ReplicaSet", "uid-replicaset", "uid-deployment"),
+				newRecoveryCandidate("deployment", "Deployment", "uid-deployment"),
+			},
+			expected: []string{"deployment", "replicaset", "pod"},
+		},
+		{
+			name: "owners not restored are ignored",
+			candidates: []recoveryCandidate{
+				newRecoveryCandidate("pod", "Pod", "uid-pod", "uid-missing"),
+				newRecoveryCandidate("service", "Service", "uid-service"),
+			},
+			expected: []string{"pod", "service"},
+		},
+		{
+			name: "candidates owning themselves",
+			candidates: []recoveryCandidate{
+				newRecoveryCandidate("configmap", "ConfigMap", "uid-configmap", "uid-configmap"),
+				newRecoveryCandidate("service", "Service", "uid-service"),
+			},
+			expected: []string{"configmap", "service"},
+		},
+		{
+			name: "ownership cycles placed in their order",
+			candidates: []recoveryCandidate{
+				newRecoveryCandidate("first", "ConfigMap", "uid-first", "uid-second"),
+				newRecoveryCandidate("second", "ConfigMap", "uid-second", "uid-first"),
+				newRecoveryCandidate("third", "ConfigMap", "uid-third", "uid-second"),
+			},
+			expected: []string{"first", "second", "third"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sortByOwnership(test.candidates)
+
+			if sorted := candidateNames(test.candidates); !reflect.DeepEqual(sorted, test.expected) {
+				t.Errorf("expected order %v, got %v", test.expected, sorted)
+			}
+		})
+	}
+}
+
+// immutableFields are the fields rejected by the API server when they change on updates, by resource
+var immutableFields = map[string][][]string{
+	"services":               {{"spec", "clusterIP"}},
+	"pods":                   {{"spec", "nodeName"}},
+	"persistentvolumeclaims": {{"spec", "volumeName"}},
+	"jobs":                   {{"spec", "selector"}, {"spec", "template"}},
+}
+
+// rejectImmutableChanges makes the fake client reject the updates and applies changing the immutable fields of
+// the live resources, like the API server does. Applies replace the live resource, as the fake tracker
+// does not support them
+func rejectImmutableChanges(dynamicClient *dynamicfake.FakeDynamicClient) {
+	dynamicClient.PrependReactor("*", "*", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		obj := &unstructured.Unstructured{}
+		isApply := false
+		switch action.GetVerb() {
+		case "update":
+			obj = action.(k8stesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		case "patch":
+			patchAction := action.(k8stesting.PatchAction)
+			if patchAction.GetPatchType() != types.ApplyPatchType {
+				return false, nil, nil
+			}
+			if err := json.Unmarshal(patchAction.GetPatch(), &obj.Object); err != nil {
+				return true, nil, err
+			}
+			isApply = true
+		default:
+			return false, nil, nil
+		}
+
+		live, err := dynamicClient.Tracker().Get(action.GetResource(), action.GetNamespace(), obj.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		for _, fields := range immutableFields[action.GetResource().Resource] {
+			liveValue, _, _ := unstructured.NestedFieldNoCopy(live.(*unstructured.Unstructured).Object, fields...)
+			value, _, _ := unstructured.NestedFieldNoCopy(obj.Object, fields...)
+			if !reflect.DeepEqual(liveValue, value) {
+				return true, nil, apierrors.NewInvalid(obj.GroupVersionKind().GroupKind(), obj.GetName(),
+					field.ErrorList{field.Invalid(field.NewPath(fields[0], fields[1:]...), value, "field is immutable")})
+			}
+		}
+
+		if isApply {
+			return true, obj, dynamicClient.Tracker().Update(action.GetResource(), obj, action.GetNamespace())
+		}
+		return false, nil, nil
+	})
+}
+
+func TestRestoreResourceOverLiveResource(t *testing.T) {
+	newPod := func(nodeName string, labels map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]interface{}{"name": "web-0", "namespace": "default", "labels": labels},
+			"spec": map[string]interface{}{
+				"nodeName":   nodeName,
+				"containers": []interface{}{map[string]interface{}{"name": "web", "image": "nginx:1.27"}},
+			},
+		}}
+	}
+	newPersistentVolumeClaim := func(volumeName string, labels map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "PersistentVolumeClaim",
+			"metadata": map[string]interface{}{
+				"name":        "data",
+				"namespace":   "default",
+				"labels":      labels,
+				"annotations": map[string]interface{}{"pv.kubernetes.io/bind-completed": "yes"},
+			},
+			"spec": map[string]interface{}{
+				"volumeName":  volumeName,
+				"accessModes": []interface{}{"ReadWriteOnce"},
+			},
+		}}
+	}
+	newJob := func(controllerUID string, labels map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"metadata":   map[string]interface{}{"name": "migrate", "namespace": "default", "labels": labels},
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels": map[string]interface{}{"batch.kubernetes.io/controller-uid": controllerUID},
+				},
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"labels": map[string]interface{}{"batch.kubernetes.io/controller-uid": controllerUID},
+					},
+					"spec": map[string]interface{}{
+						"restartPolicy": "Never",
+						"containers":    []interface{}{map[string]interface{}{"name": "migrate", "image": "migrate:1"}},
+					},
+				},
+			},
+		}}
+	}
+
+	liveLabels := map[string]interface{}{"app": "web"}
+	savedLabels := map[string]interface{}{"app": "web", "tier": "frontend"}
+
+	tests := []struct {
+		name     string
+		live     *unstructured.Unstructured
+		captured *unstructured.Unstructured
+		strategy string
+		result   string
+	}{
+		{
+			name:     "Pod replaced on the node it is scheduled to",
+			live:     newPod("node-1", liveLabels),
+			captured: newPod("node-0", savedLabels),
+			strategy: conflictStrategyReplace,
+			result:   restoreResultReplaced,
+		},
+		{
+			name:     "Pod applied on the node it is scheduled to",
+			live:     newPod("node-1", liveLabels),
+			captured: newPod("node-0", savedLabels),
+			strategy: conflictStrategyApply,
+			result:   restoreResultApplied,
+		},
+		{
+			name:     "PersistentVolumeClaim replaced on its bound volume",
+			live:     newPersistentVolumeClaim("pvc-live", liveLabels),
+			captured: newPersistentVolumeClaim("pvc-captured", savedLabels),
+			strategy: conflictStrategyReplace,
+			result:   restoreResultReplaced,
+		},
+		{
+			name:     "PersistentVolumeClaim applied on its bound volume",
+			live:     newPersistentVolumeClaim("pvc-live", liveLabels),
+			captured: newPersistentVolumeClaim("pvc-captured", savedLabels),
+			strategy: conflictStrategyApply,
+			result:   restoreResultApplied,
+		},
+		{
+			name:     "Job replaced with its generated selector",
+			live:     newJob("uid-live", liveLabels),
+			captured: newJob("uid-captured", savedLabels),
+			strategy: conflictStrategyReplace,
+			result:   restoreResultReplaced,
+		},
+		{
+			name:     "Job applied with its generated selector",
+			live:     newJob("uid-live", liveLabels),
+			captured: newJob("uid-captured", savedLabels),
+			strategy: conflictStrategyApply,
+			result:   restoreResultApplied,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			dynamicClient := setupFakeClients(t, test.live.DeepCopy())
+			rejectImmutableChanges(dynamicClient)
+
+			resourceToRestore := test.captured.DeepCopy()
+			sanitizeResource(resourceToRestore)
+
+			result, err := restoreResource(context.Background(), resourceToRestore, test.strategy, false)
+			if err != nil {
+				t.Fatalf("unexpected error restoring the resource: %v", err)
+			}
+			if result != test.result {
+				t.Errorf("expected result %s, got %s", test.result, result)
+			}
+
+			gvr, _ := meta.UnsafeGuessKindToResource(test.live.GroupVersionKind())
+			restored, err := dynamicClient.Tracker().Get(gvr, test.live.GetNamespace(), test.live.GetName())
+			if err != nil {
+				t.Fatalf("unexpected error getting the restored resource: %v", err)
+			}
+			restoredLabels := restored.(*unstructured.Unstructured).GetLabels()
+			if restoredLabels["tier"] != "frontend" {
+				t.Errorf("expected the labels of the captured resource, got %v", restoredLabels)
+			}
+		})
+	}
+}
+
+func TestRestoreRecoveryResourceSkipUnchanged(t *testing.T) {
+	newPod := func(nodeName, tier string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata": map[string]interface{}{
+				"name":      "web-0",
+				"namespace": "default",
+				"labels":    map[string]interface{}{"app": "web", "tier": tier},
+			},
+			"spec": map[string]interface{}{
+				"nodeName":   nodeName,
+				"containers": []interface{}{map[string]interface{}{"name": "web", "image": "nginx:1.27"}},
+			},
+		}}
+	}
+	newLivePod := func(nodeName, tier string) *unstructured.Unstructured {
+		pod := newPod(nodeName, tier)
+		pod.SetUID("uid-live")
+		pod.SetResourceVersion("42")
+		_ = unstructured.SetNestedField(pod.Object, "Running", "status", "phase")
+		return pod
+	}
+
+	tests := []struct {
+		name            string
+		live            *unstructured.Unstructured
+		captured        *unstructured.Unstructured
+		expectedResult  string
+		expectedUpdates int
+	}{
+		{
+			name:           "live resource with the captured content, scheduled to another node",
+			live:           newLivePod("node-1", "frontend"),
+			captured:       newPod("node-0", "frontend"),
+			expectedResult: restoreResultUnchanged,
+		},
+		{
+			name:            "live resource changed after the capture",
+			live:            newLivePod("node-1", "backend"),
+			captured:        newPod("node-0", "frontend"),
+			expectedResult:  restoreResultReplaced,
+			expectedUpdates: 1,
+		},
+		{
+			name:           "missing live resource",
+			captured:       newPod("node-0", "frontend"),
+			expectedResult: restoreResultCreated,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var objects []runtime.Object
+			if test.live != nil {
+				objects = append(objects, test.live.DeepCopy())
+			}
+			dynamicClient := setupFakeClients(t, objects...)
+
+			payload, err := json.Marshal(test.captured.Object)
+			if err != nil {
+				t.Fatalf("unexpected error encoding the payload: %v", err)
+			}
+			recoveryResource := &kuberecoveryv1alpha1.RecoveryResource{Spec: runtime.RawExtension{Raw: payload}}
+			recoveryResource.SetName("recoveryconfig-sample-pod-web-0")
+
+			lastRestore, err := restoreRecoveryResource(context.Background(), nil, recoveryResource, restoreRequest{
+				conflictStrategy: conflictStrategyReplace,
+				skipUnchanged:    true,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error restoring the resource: %v", err)
+			}
+			if lastRestore.Result != test.expectedResult {
+				t.Errorf("expected result %s, got %s", test.expectedResult, lastRestore.Result)
+			}
+			if test.expectedResult == restoreResultUnchanged && lastRestore.UID != string(test.live.GetUID()) {
+				t.Errorf("expected the UID of the live resource %s, got %s", test.live.GetUID(), lastRestore.UID)
+			}
+
+			updates := 0
+			for _, action := range dynamicClient.Actions() {
+				if action.GetVerb() == "update" {
+					updates++
+				}
+			}
+			if updates != test.expectedUpdates {
+				t.Errorf("expected %d updates, got %d", test.expectedUpdates, updates)
+			}
+		})
+	}
+}
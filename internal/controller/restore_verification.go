@@ -0,0 +1,323 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
+)
+
+// healthCheckFunc checks the health of a live resource. A resource that is not healthy yet may become healthy later,
+// unless it failed
+type healthCheckFunc func(obj *unstructured.Unstructured) (healthy, failed bool, message string)
+
+// healthChecks are the checks of the kinds with a rollout or a completion to wait for.
+// Other kinds are checked with their Ready condition, if any
+var healthChecks = map[schema.GroupKind]healthCheckFunc{
+	{Group: "apps", Kind: "Deployment"}:  checkDeploymentHealth,
+	{Group: "apps", Kind: "StatefulSet"}: checkStatefulSetHealth,
+	{Group: "apps", Kind: "DaemonSet"}:   checkDaemonSetHealth,
+	{Group: "batch", Kind: "Job"}:        checkJobHealth,
+	{Group: "", Kind: "Pod"}:             checkPodHealth,
+}
+
+// results of the restore after which the restored resource is verified
+var resultsVerified = []string{
+	restoreResultCreated,
+	restoreResultReplaced,
+	restoreResultApplied,
+	restoreResultRenamed,
+}
+
+// results of the restore after which the unhealthy restored resource can be rolled back by deleting it, as it did not
+// exist before. Replaced and applied resources had a previous state that deleting them would lose
+var resultsRolledBack = []string{
+	restoreResultCreated,
+	restoreResultRenamed,
+}
+
+// getRestoreVerificationTimeout returns the time the resource restored from the RecoveryResource has to become
+// healthy, when the verification is requested in its annotations
+func getRestoreVerificationTimeout(
+	recoveryResource *kuberecoveryv1alpha1.RecoveryResource) (timeout time.Duration, verify bool, err error) {
+
+	timeoutAnnotation, verify := recoveryResource.GetAnnotations()[recoveryResourceRestoreVerifyTimeoutAnnotation]
+	if !verify {
+		return timeout, verify, nil
+	}
+
+	timeout, err = parseDurationWithDays(timeoutAnnotation)
+	if err != nil {
+		return timeout, verify, fmt.Errorf(parseVerifyTimeoutError, timeoutAnnotation, recoveryResource.Name, err)
+	}
+	return timeout, verify, nil
+}
+
+// newRestoreVerification returns a pending verification of the restored resource, finishing after the timeout
+func newRestoreVerification(timeout time.Duration) *kuberecoveryv1alpha1.RestoreVerificationT {
+	return &kuberecoveryv1alpha1.RestoreVerificationT{
+		Result:   verificationResultPending,
+		Message:  verificationStartedMessage,
+		Deadline: metav1.NewTime(time.Now().Add(timeout)),
+	}
+}
+
+// isRestoreVerificationPending returns true if the resource restored from the RecoveryResource is being verified
+func isRestoreVerificationPending(recoveryResource *kuberecoveryv1alpha1.RecoveryResource) bool {
+	lastRestore := recoveryResource.Status.LastRestore
+	return lastRestore != nil && lastRestore.Verification != nil &&
+		lastRestore.Verification.Result == verificationResultPending
+}
+
+// verifyRestore checks the health of the resource restored from the RecoveryResource. The verification finishes when
+// the resource is healthy, when it fails or when the deadline is reached. Unhealthy resources created by the restore
+// are deleted when the rollback is requested in the annotations of the RecoveryResource
+func (r *RecoveryResourceReconciler) verifyRestore(ctx context.Context,
+	recoveryResource *kuberecoveryv1alpha1.RecoveryResource) error {
+
+	logger := log.FromContext(ctx)
+	lastRestore := recoveryResource.Status.LastRestore
+	verification := lastRestore.Verification
+
+	restored := &unstructured.Unstructured{}
+	restored.SetAPIVersion(lastRestore.APIVersion)
+	restored.SetKind(lastRestore.Kind)
+	restored.SetNamespace(lastRestore.Namespace)
+	restored.SetName(lastRestore.Name)
+
+	dynamicClient, err := getResourceClient(restored)
+	if err != nil {
+		return err
+	}
+
+	// The verification finishes when the restored resource is gone, as there is nothing left to verify
+	liveResource, err := dynamicClient.Get(ctx, lastRestore.Name, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf(getLiveResourceError, lastRestore.Name, err)
+	}
+
+	var healthy, failed bool
+	var message string
+	switch {
+	case apierrors.IsNotFound(err):
+		failed, message = true, restoredResourceGoneMessage
+	case string(liveResource.GetUID()) != lastRestore.UID:
+		failed, message = true, fmt.Sprintf(restoredResourceReplacedMessage, liveResource.GetUID())
+	default:
+		healthy, failed, message = checkResourceHealth(liveResource)
+	}
+
+	if !healthy && !failed && time.Now().Before(verification.Deadline.Time) {
+		verification.Message = message
+		r.UpdateConditionRestoreVerification(recoveryResource, verification)
+		return nil
+	}
+
+	if !healthy && !failed {
+		message = fmt.Sprintf(verificationTimeoutMessage,
+			verification.Deadline.Sub(lastRestore.Time.Time).Round(time.Second), message)
+	}
+
+	result := verificationResultHealthy
+	if !healthy {
+		result = verificationResultUnhealthy
+	}
+
+	// Only the resource created by the restore is deleted, never one that replaced it later nor one that existed
+	// before the restore. The verification is kept pending when the deletion fails, so it is tried again
+	rollback := recoveryResource.GetAnnotations()[recoveryResourceRestoreRollbackAnnotation] ==
+		recoveryResourceRestoreRollbackAnnotationValue
+	if !healthy && rollback && !slices.Contains(resultsRolledBack, lastRestore.Result) {
+		message = fmt.Sprintf(rollbackSkippedMessage, message, lastRestore.Result)
+		rollback = false
+	}
+	if !healthy && rollback && liveResource != nil && string(liveResource.GetUID()) == lastRestore.UID {
+		uid := types.UID(lastRestore.UID)
+		propagationPolicy := metav1.DeletePropagationBackground
+		err = dynamicClient.Delete(ctx, lastRestore.Name, metav1.DeleteOptions{
+			Preconditions:     &metav1.Preconditions{UID: &uid},
+			PropagationPolicy: &propagationPolicy,
+		})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf(rollbackResourceError, lastRestore.Name, err)
+		}
+		result = verificationResultRolledBack
+		message = fmt.Sprintf(resourceRolledBackMessage, message)
+	}
+
+	now := metav1.Now()
+	verification.Result = result
+	verification.Message = message
+	verification.CompletionTime = &now
+
+	logger.Info(fmt.Sprintf(resourceVerifiedMessage, recoveryResource.Name, lastRestore.Namespace, lastRestore.Name,
+		verification.Result, verification.Message))
+	r.UpdateConditionRestoreVerification(recoveryResource, verification)
+	return nil
+}
+
+// checkResourceHealth checks the health of the live resource with the check of its kind,
+// or with its Ready condition when its kind has no check
+func checkResourceHealth(obj *unstructured.Unstructured) (healthy, failed bool, message string) {
+	if check, exists := healthChecks[obj.GroupVersionKind().GroupKind()]; exists {
+		return check(obj)
+	}
+	return checkReadyCondition(obj)
+}
+
+// checkDeploymentHealth waits for the rollout of the Deployment, failing when its progress deadline is exceeded
+func checkDeploymentHealth(obj *unstructured.Unstructured) (healthy, failed bool, message string) {
+	if generation, observed := isGenerationObserved(obj); !observed {
+		return false, false, fmt.Sprintf(generationNotObservedMessage, generation)
+	}
+
+	status, reason, conditionMessage, _ := getStatusCondition(obj, "Progressing")
+	if status == string(metav1.ConditionFalse) && reason == "ProgressDeadlineExceeded" {
+		return false, true, fmt.Sprintf(conditionNotTrueMessage, "Progressing", status, conditionMessage)
+	}
+
+	replicas := getDesiredReplicas(obj)
+	updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "availableReplicas")
+	total, _, _ := unstructured.NestedInt64(obj.Object, "status", "replicas")
+	if updated < replicas || available < replicas || total > updated {
+		return false, false, fmt.Sprintf(replicasNotReadyMessage, updated, replicas, available)
+	}
+	return true, false, resourceHealthyMessage
+}
+
+// checkStatefulSetHealth waits for the replicas of the StatefulSet to be updated and ready
+func checkStatefulSetHealth(obj *unstructured.Unstructured) (healthy, failed bool, message string) {
+	if generation, observed := isGenerationObserved(obj); !observed {
+		return false, false, fmt.Sprintf(generationNotObservedMessage, generation)
+	}
+
+	replicas := getDesiredReplicas(obj)
+	updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedReplicas")
+	ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+
+	// Replicas are not updated by the controller when the update strategy is OnDelete
+	strategy, _, _ := unstructured.NestedString(obj.Object, "spec", "updateStrategy", "type")
+	if strategy == "OnDelete" {
+		updated = replicas
+	}
+	if updated < replicas || ready < replicas {
+		return false, false, fmt.Sprintf(replicasNotReadyMessage, updated, replicas, ready)
+	}
+	return true, false, resourceHealthyMessage
+}
+
+// checkDaemonSetHealth waits for the Pods of the DaemonSet to be updated and available in every node scheduled
+func checkDaemonSetHealth(obj *unstructured.Unstructured) (healthy, failed bool, message string) {
+	if generation, observed := isGenerationObserved(obj); !observed {
+		return false, false, fmt.Sprintf(generationNotObservedMessage, generation)
+	}
+
+	desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+	updated, _, _ := unstructured.NestedInt64(obj.Object, "status", "updatedNumberScheduled")
+	available, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberAvailable")
+	if updated < desired || available < desired {
+		return false, false, fmt.Sprintf(replicasNotReadyMessage, updated, desired, available)
+	}
+	return true, false, resourceHealthyMessage
+}
+
+// checkJobHealth waits for the Job to complete, failing when it fails
+func checkJobHealth(obj *unstructured.Unstructured) (healthy, failed bool, message string) {
+	status, _, conditionMessage, _ := getStatusCondition(obj, "Failed")
+	if status == string(metav1.ConditionTrue) {
+		return false, true, fmt.Sprintf(conditionNotTrueMessage, "Failed", status, conditionMessage)
+	}
+
+	status, _, conditionMessage, _ = getStatusCondition(obj, "Complete")
+	if status != string(metav1.ConditionTrue) {
+		return false, false, fmt.Sprintf(conditionNotTrueMessage, "Complete", status, conditionMessage)
+	}
+	return true, false, resourceHealthyMessage
+}
+
+// checkPodHealth waits for the Pod to be ready or to succeed, failing when it fails
+func checkPodHealth(obj *unstructured.Unstructured) (healthy, failed bool, message string) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Succeeded":
+		return true, false, resourceHealthyMessage
+	case "Failed":
+		reason, _, _ := unstructured.NestedString(obj.Object, "status", "message")
+		return false, true, fmt.Sprintf(podFailedMessage, reason)
+	}
+	return checkReadyCondition(obj)
+}
+
+// checkReadyCondition waits for the Ready condition of the resource to be true.
+// Resources without a Ready condition are healthy as soon as they exist
+func checkReadyCondition(obj *unstructured.Unstructured) (healthy, failed bool, message string) {
+	status, _, conditionMessage, found := getStatusCondition(obj, "Ready")
+	if found && status != string(metav1.ConditionTrue) {
+		return false, false, fmt.Sprintf(conditionNotTrueMessage, "Ready", status, conditionMessage)
+	}
+	return true, false, resourceHealthyMessage
+}
+
+// isGenerationObserved returns the generation of the resource and whether its controller already observed it
+func isGenerationObserved(obj *unstructured.Unstructured) (generation int64, observed bool) {
+	observedGeneration, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+	return obj.GetGeneration(), observedGeneration >= obj.GetGeneration()
+}
+
+// getDesiredReplicas returns the replicas set in the spec of the resource, defaulting to 1
+func getDesiredReplicas(obj *unstructured.Unstructured) int64 {
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		return 1
+	}
+	return replicas
+}
+
+// getStatusCondition returns the status, reason and message of the condition of the resource with the type
+func getStatusCondition(obj *unstructured.Unstructured,
+	conditionType string) (status, reason, message string, found bool) {
+
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, condition := range conditions {
+		conditionMap, ok := condition.(map[string]interface{})
+		if !ok || conditionMap["type"] != conditionType {
+			continue
+		}
+		status, _, _ = unstructured.NestedString(conditionMap, "status")
+		reason, _, _ = unstructured.NestedString(conditionMap, "reason")
+		message, _, _ = unstructured.NestedString(conditionMap, "message")
+		return status, reason, message, true
+	}
+	return status, reason, message, false
+}
+
+// isVerifiedResult returns true if the resource is verified after a restore with the result
+func isVerifiedResult(result string) bool {
+	return slices.Contains(resultsVerified, result)
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// movedKinds are the kinds served from another group after the removal of their deprecated versions
+var movedKinds = map[schema.GroupKind]string{
+	{Group: "extensions", Kind: "Deployment"}:        "apps",
+	{Group: "extensions", Kind: "DaemonSet"}:         "apps",
+	{Group: "extensions", Kind: "ReplicaSet"}:        "apps",
+	{Group: "extensions", Kind: "Ingress"}:           "networking.k8s.io",
+	{Group: "extensions", Kind: "NetworkPolicy"}:     "networking.k8s.io",
+	{Group: "extensions", Kind: "PodSecurityPolicy"}: "policy",
+}
+
+// getServedGroupVersionKind returns the group, version and kind the resource is served with. It is the given one
+// while its version is served, or the preferred version of the kind when it is not served anymore
+func getServedGroupVersionKind(gvk schema.GroupVersionKind) (schema.GroupVersionKind, error) {
+
+	_, err := getRESTMapping(gvk.GroupKind(), gvk.Version)
+	if err == nil {
+		return gvk, nil
+	}
+	if !meta.IsNoMatchError(err) {
+		return gvk, err
+	}
+
+	// The version is not served anymore, so the preferred version of the kind is used instead,
+	// looking for it in the group the kind was moved to, if any
+	groupKind := gvk.GroupKind()
+	mapping, err := getRESTMapping(groupKind)
+	if meta.IsNoMatchError(err) {
+		if group, moved := movedKinds[groupKind]; moved {
+			mapping, err = getRESTMapping(schema.GroupKind{Group: group, Kind: gvk.Kind})
+		}
+	}
+	if err != nil {
+		return gvk, fmt.Errorf(versionNotServedError, gvk.GroupVersion().String(), gvk.Kind, err)
+	}
+
+	return mapping.GroupVersionKind, nil
+}
+
+// convertToServedVersion changes the apiVersion of the resource to the one served by the cluster, when the captured
+// one is not served anymore. The schemas of both versions must be compatible, so the converted resource is validated
+// by the API server with a strict dry run. It returns the captured apiVersion when the resource was converted
+func convertToServedVersion(ctx context.Context, obj *unstructured.Unstructured) (convertedFrom string, err error) {
+
+	capturedGVK := obj.GroupVersionKind()
+	servedGVK, err := getServedGroupVersionKind(capturedGVK)
+	if err != nil {
+		return convertedFrom, err
+	}
+	if servedGVK == capturedGVK {
+		return convertedFrom, nil
+	}
+
+	convertedFrom = obj.GetAPIVersion()
+	obj.SetGroupVersionKind(servedGVK)
+
+	dynamicClient, err := getResourceClient(obj)
+	if err != nil {
+		return convertedFrom, err
+	}
+
+	// The resource may already exist, but then its content was still validated before the conflict was detected
+	_, err = dynamicClient.Create(ctx, obj, metav1.CreateOptions{
+		DryRun:          []string{metav1.DryRunAll},
+		FieldValidation: metav1.FieldValidationStrict,
+	})
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return convertedFrom, fmt.Errorf(incompatibleVersionError, convertedFrom, obj.GetAPIVersion(), err)
+	}
+
+	return convertedFrom, nil
+}
@@ -31,9 +31,16 @@ const (
 	ConditionReasonTargetSynced        = "TargetSynced"
 	ConditionReasonTargetSyncedMessage = "Target was successfully synced"
 
+	// Restore completed with some resources not restored
+	ConditionReasonRestorePartiallyFailed = "RestorePartiallyFailed"
+
 	// Kubernetes error type
 	ConditionReasonKubernetesApiCallErrorType    = "KubernetesApiCallError"
 	ConditionReasonKubernetesApiCallErrorMessage = "Call to Kubernetes API failed. More info in logs."
+
+	// Condition type for the verification of the health of the restored resource.
+	// Its reason is the result of the verification
+	ConditionTypeRestoreVerified = "RestoreVerified"
 )
 
 var (
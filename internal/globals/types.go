@@ -31,6 +31,6 @@ type applicationT struct {
 	Context context.Context
 
 	// Kubernetes clients
-	KubeRawClient     *dynamic.DynamicClient
-	KubeRawCoreClient *kubernetes.Clientset
+	KubeRawClient     dynamic.Interface
+	KubeRawCoreClient kubernetes.Interface
 }
@@ -19,6 +19,8 @@ package pools
 import (
 	"sync"
 
+	"k8s.io/apimachinery/pkg/labels"
+
 	kuberecoveryv1alpha1 "freepik.com/kuberecovery/api/v1alpha1"
 )
 
@@ -29,10 +31,17 @@ type ResourceWatcher struct {
 	APIVersion     string
 	Namespace      string
 	Chan           chan struct{}
+
+	// LabelSelector of the resources listed and watched by the informer, or nil to watch all of them
+	LabelSelector labels.Selector
+
+	// NamespaceSelector of the namespaces whose events are handled, when the informer watches all namespaces
+	// on behalf of a namespace selector, or nil to handle all of them
+	NamespaceSelector labels.Selector
 }
 
 var (
-	ResourceWatcherPoolKeyFormat = "%s/%s/%s/%s"
+	ResourceWatcherPoolKeyFormat = "%s/%s/%s/%s/%s"
 )
 
 // ResourceWatcherStore
@@ -57,7 +66,13 @@ func (c *ResourceWatcherStore) Get(key string) (*ResourceWatcher, bool) {
 func (c *ResourceWatcherStore) GetAll() map[string]*ResourceWatcher {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.Store
+
+	// Return a copy, so callers can range over it while the pool is modified
+	watchers := make(map[string]*ResourceWatcher, len(c.Store))
+	for key, watcher := range c.Store {
+		watchers[key] = watcher
+	}
+	return watchers
 }
 
 func (c *ResourceWatcherStore) Delete(key string) {
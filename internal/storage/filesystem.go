@@ -0,0 +1,115 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	filesystemReferencePrefix = "filesystem://"
+
+	filesystemPathEmptyError = "the filesystem backend needs a base path"
+)
+
+// FilesystemBackend stores the payloads as files inside a directory, usually a mounted PVC
+type FilesystemBackend struct {
+	basePath string
+}
+
+// NewFilesystemBackend returns a FilesystemBackend rooted at basePath, creating the directory when needed
+func NewFilesystemBackend(basePath string) (*FilesystemBackend, error) {
+	if basePath == "" {
+		return nil, errors.New(filesystemPathEmptyError)
+	}
+
+	err := os.MkdirAll(basePath, 0o750)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FilesystemBackend{basePath: basePath}, nil
+}
+
+// Put writes the payload into a temporary file and renames it, so readers never get a partial payload
+func (b *FilesystemBackend) Put(_ context.Context, key string, payload []byte) (ref string, err error) {
+	if err = validateKey(key); err != nil {
+		return ref, err
+	}
+
+	tmpFile, err := os.CreateTemp(b.basePath, "."+key+"-*")
+	if err != nil {
+		return ref, err
+	}
+	defer func() {
+		_ = os.Remove(tmpFile.Name())
+	}()
+
+	if _, err = tmpFile.Write(payload); err != nil {
+		_ = tmpFile.Close()
+		return ref, err
+	}
+	if err = tmpFile.Close(); err != nil {
+		return ref, err
+	}
+
+	if err = os.Rename(tmpFile.Name(), filepath.Join(b.basePath, key)); err != nil {
+		return ref, err
+	}
+
+	return filesystemReferencePrefix + key, nil
+}
+
+// Get reads the payload stored under the reference
+func (b *FilesystemBackend) Get(_ context.Context, ref string) ([]byte, error) {
+	key, err := b.keyFromReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(filepath.Join(b.basePath, key))
+}
+
+// Delete removes the payload stored under the reference. Missing payloads are not considered an error
+func (b *FilesystemBackend) Delete(_ context.Context, ref string) error {
+	key, err := b.keyFromReference(ref)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(filepath.Join(b.basePath, key))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// keyFromReference extracts the file name from a reference returned by Put
+func (b *FilesystemBackend) keyFromReference(ref string) (string, error) {
+	if !strings.HasPrefix(ref, filesystemReferencePrefix) {
+		return "", fmt.Errorf(invalidReferenceError, ref, BackendTypeFilesystem)
+	}
+
+	key := strings.TrimPrefix(ref, filesystemReferencePrefix)
+	return key, validateKey(key)
+}
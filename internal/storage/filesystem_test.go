@@ -0,0 +1,148 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFilesystemBackend(t *testing.T) {
+	tests := []struct {
+		name             string
+		key              string
+		payload          []byte
+		expectedRef      string
+		expectedPutError bool
+	}{
+		{
+			name:        "payload stored under the key",
+			key:         "recoveryconfig-sample-service-test.json",
+			payload:     []byte(`{"kind":"Service"}`),
+			expectedRef: "filesystem://recoveryconfig-sample-service-test.json",
+		},
+		{
+			name:        "empty payload",
+			key:         "recoveryconfig-sample-configmap-empty.json",
+			payload:     []byte{},
+			expectedRef: "filesystem://recoveryconfig-sample-configmap-empty.json",
+		},
+		{
+			name:             "empty key",
+			key:              "",
+			expectedPutError: true,
+		},
+		{
+			name:             "key with a path separator",
+			key:              "nested/payload.json",
+			expectedPutError: true,
+		},
+		{
+			name:             "key escaping the base path",
+			key:              "..",
+			expectedPutError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			basePath := filepath.Join(t.TempDir(), "payloads")
+			backend, err := NewFilesystemBackend(basePath)
+			if err != nil {
+				t.Fatalf("unexpected error creating the backend: %v", err)
+			}
+			ctx := context.Background()
+
+			ref, err := backend.Put(ctx, test.key, test.payload)
+			if test.expectedPutError {
+				if err == nil {
+					t.Fatal("expected an error putting the payload, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error putting the payload: %v", err)
+			}
+			if ref != test.expectedRef {
+				t.Errorf("expected reference %s, got %s", test.expectedRef, ref)
+			}
+
+			payload, err := backend.Get(ctx, ref)
+			if err != nil {
+				t.Fatalf("unexpected error getting the payload: %v", err)
+			}
+			if string(payload) != string(test.payload) {
+				t.Errorf("expected payload %s, got %s", test.payload, payload)
+			}
+
+			// No temporary file is left behind the payload
+			entries, err := os.ReadDir(basePath)
+			if err != nil {
+				t.Fatalf("unexpected error reading the base path: %v", err)
+			}
+			if len(entries) != 1 {
+				t.Errorf("expected just the payload in the base path, got %d files", len(entries))
+			}
+
+			if err = backend.Delete(ctx, ref); err != nil {
+				t.Fatalf("unexpected error deleting the payload: %v", err)
+			}
+			if _, err = backend.Get(ctx, ref); err == nil {
+				t.Error("expected an error getting a deleted payload, got none")
+			}
+			if err = backend.Delete(ctx, ref); err != nil {
+				t.Errorf("unexpected error deleting a missing payload: %v", err)
+			}
+		})
+	}
+}
+
+func TestFilesystemBackendInvalidReferences(t *testing.T) {
+	backend, err := NewFilesystemBackend(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error creating the backend: %v", err)
+	}
+
+	tests := []struct {
+		name string
+		ref  string
+	}{
+		{name: "reference of another backend", ref: "s3://captures/payload.json"},
+		{name: "reference without prefix", ref: "payload.json"},
+		{name: "reference escaping the base path", ref: "filesystem://../payload.json"},
+		{name: "reference to a nested file", ref: "filesystem://nested/payload.json"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := backend.Get(context.Background(), test.ref); err == nil {
+				t.Error("expected an error getting the payload, got none")
+			}
+			if err := backend.Delete(context.Background(), test.ref); err == nil {
+				t.Error("expected an error deleting the payload, got none")
+			}
+		})
+	}
+}
+
+func TestNewFilesystemBackend(t *testing.T) {
+	if _, err := NewFilesystemBackend(""); err == nil {
+		t.Error("expected an error without base path, got none")
+	}
+}
@@ -0,0 +1,228 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	s3ReferencePrefix = "s3://"
+
+	s3DefaultRegion   = "us-east-1"
+	s3EndpointFormat  = "s3.%s.amazonaws.com"
+	s3RequestTimeout  = 30 * time.Second
+	s3SigningAlgo     = "AWS4-HMAC-SHA256"
+	s3SignedHeaders   = "host;x-amz-content-sha256;x-amz-date"
+	s3AmzDateFormat   = "20060102T150405Z"
+	s3ShortDateFormat = "20060102"
+
+	s3BucketEmptyError     = "the s3 backend needs a bucket"
+	s3CredentialsError     = "the s3 backend needs an access key id and a secret access key"
+	s3UnexpectedStatusCode = "unexpected status code %d on %s %s: %s"
+)
+
+// S3Backend stores the payloads as objects in an S3-compatible bucket (AWS S3, MinIO, Ceph...).
+// Requests are signed with AWS Signature Version 4 and use path-style addressing
+type S3Backend struct {
+	endpoint        string
+	bucket          string
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+	scheme          string
+	httpClient      *http.Client
+}
+
+// NewS3Backend returns a S3Backend for the bucket. When endpoint is empty, the AWS endpoint for the region is used
+func NewS3Backend(endpoint, bucket, region, accessKeyID, secretAccessKey string, insecure bool) (*S3Backend, error) {
+	if bucket == "" {
+		return nil, errors.New(s3BucketEmptyError)
+	}
+	if accessKeyID == "" || secretAccessKey == "" {
+		return nil, errors.New(s3CredentialsError)
+	}
+
+	if region == "" {
+		region = s3DefaultRegion
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf(s3EndpointFormat, region)
+	}
+
+	scheme := "https"
+	if insecure {
+		scheme = "http"
+	}
+
+	return &S3Backend{
+		endpoint:        strings.TrimSuffix(endpoint, "/"),
+		bucket:          bucket,
+		region:          region,
+		accessKeyID:     accessKeyID,
+		secretAccessKey: secretAccessKey,
+		scheme:          scheme,
+		httpClient:      &http.Client{Timeout: s3RequestTimeout},
+	}, nil
+}
+
+// Put uploads the payload as an object named after the key
+func (b *S3Backend) Put(ctx context.Context, key string, payload []byte) (ref string, err error) {
+	if err = validateKey(key); err != nil {
+		return ref, err
+	}
+
+	_, err = b.do(ctx, http.MethodPut, key, payload, http.StatusOK)
+	if err != nil {
+		return ref, err
+	}
+
+	return s3ReferencePrefix + b.bucket + "/" + key, nil
+}
+
+// Get downloads the object stored under the reference
+func (b *S3Backend) Get(ctx context.Context, ref string) ([]byte, error) {
+	key, err := b.keyFromReference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.do(ctx, http.MethodGet, key, nil, http.StatusOK)
+}
+
+// Delete removes the object stored under the reference. Missing objects are not considered an error
+func (b *S3Backend) Delete(ctx context.Context, ref string) error {
+	key, err := b.keyFromReference(ref)
+	if err != nil {
+		return err
+	}
+
+	_, err = b.do(ctx, http.MethodDelete, key, nil, http.StatusNoContent, http.StatusOK, http.StatusNotFound)
+	return err
+}
+
+// keyFromReference extracts the object name from a reference returned by Put
+func (b *S3Backend) keyFromReference(ref string) (string, error) {
+	prefix := s3ReferencePrefix + b.bucket + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return "", fmt.Errorf(invalidReferenceError, ref, BackendTypeS3)
+	}
+
+	key := strings.TrimPrefix(ref, prefix)
+	return key, validateKey(key)
+}
+
+// do sends a signed request for the object and returns the response body when the status code is expected
+func (b *S3Backend) do(ctx context.Context, method, key string, body []byte, expectedStatus ...int) ([]byte, error) {
+	objectPath := "/" + url.PathEscape(b.bucket) + "/" + url.PathEscape(key)
+	requestURL := b.scheme + "://" + b.endpoint + objectPath
+
+	req, err := http.NewRequestWithContext(ctx, method, requestURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	b.sign(req, objectPath, body, time.Now().UTC())
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, status := range expectedStatus {
+		if resp.StatusCode == status {
+			return respBody, nil
+		}
+	}
+
+	return nil, fmt.Errorf(s3UnexpectedStatusCode, resp.StatusCode, method, requestURL, string(respBody))
+}
+
+// sign adds the AWS Signature Version 4 headers to the request
+// Ref: https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+func (b *S3Backend) sign(req *http.Request, canonicalURI string, body []byte, now time.Time) {
+	amzDate := now.Format(s3AmzDateFormat)
+	shortDate := now.Format(s3ShortDateFormat)
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	canonicalHeaders := "host:" + req.URL.Host + "\n" +
+		"x-amz-content-sha256:" + payloadHash + "\n" +
+		"x-amz-date:" + amzDate + "\n"
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		"",
+		canonicalHeaders,
+		s3SignedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := shortDate + "/" + b.region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{
+		s3SigningAlgo,
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := deriveSigningKey(b.secretAccessKey, shortDate, b.region, "s3")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s3SigningAlgo, b.accessKeyID, scope, s3SignedHeaders, signature))
+}
+
+// deriveSigningKey returns the key signing the requests to the service in the region on the date
+func deriveSigningKey(secretAccessKey, shortDate, region, service string) []byte {
+	signingKey := hmacSHA256([]byte("AWS4"+secretAccessKey), shortDate)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	return hmacSHA256(signingKey, "aws4_request")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
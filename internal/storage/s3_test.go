@@ -0,0 +1,276 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKeyID     = "AKIDEXAMPLE"
+	testSecretAccessKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+)
+
+func TestDeriveSigningKey(t *testing.T) {
+	tests := []struct {
+		name      string
+		shortDate string
+		region    string
+		service   string
+		expected  string
+	}{
+		{
+			// Ref: https://docs.aws.amazon.com/IAM/latest/UserGuide/signing-elements.html
+			name:      "AWS documentation example",
+			shortDate: "20120215",
+			region:    "us-east-1",
+			service:   "iam",
+			expected:  "f4780e2d9f65fa895f9c67b32ce1baf0b0d8a43505a000a1a9e090d414db404d",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			signingKey := deriveSigningKey(testSecretAccessKey, test.shortDate, test.region, test.service)
+			if got := hex.EncodeToString(signingKey); got != test.expected {
+				t.Errorf("expected signing key %s, got %s", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestS3BackendSign(t *testing.T) {
+	now := time.Date(2025, 1, 30, 15, 10, 1, 0, time.UTC)
+
+	tests := []struct {
+		name                  string
+		endpoint              string
+		region                string
+		method                string
+		body                  []byte
+		expectedPayloadHash   string
+		expectedAuthorization string
+	}{
+		{
+			name:                "GET without body on a custom endpoint",
+			endpoint:            "minio.example.com:9000",
+			region:              "us-east-1",
+			method:              http.MethodGet,
+			expectedPayloadHash: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			expectedAuthorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250130/us-east-1/s3/aws4_request, " +
+				"SignedHeaders=host;x-amz-content-sha256;x-amz-date, " +
+				"Signature=2213947eac46cdee74eaa781b07de426dc3f288e0bd58c3b2af940eb9d9b9bcc",
+		},
+		{
+			name:                "PUT with body on the AWS endpoint of the region",
+			region:              "eu-west-1",
+			method:              http.MethodPut,
+			body:                []byte(`{"kind":"Service"}`),
+			expectedPayloadHash: "44feaba663f323f449ed27751d9f366141efb7bb5837ce4f8e63cced02636033",
+			expectedAuthorization: "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20250130/eu-west-1/s3/aws4_request, " +
+				"SignedHeaders=host;x-amz-content-sha256;x-amz-date, " +
+				"Signature=1d294f76499f43c11959830a8f33c2ffd6f2f040a997096920e056675cb0f5f9",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend, err := NewS3Backend(test.endpoint, "captures", test.region, testAccessKeyID, testSecretAccessKey,
+				false)
+			if err != nil {
+				t.Fatalf("unexpected error creating the backend: %v", err)
+			}
+
+			objectPath := "/captures/recoveryconfig-sample-service-test.json"
+			req, err := http.NewRequest(test.method, "https://"+backend.endpoint+objectPath, nil)
+			if err != nil {
+				t.Fatalf("unexpected error creating the request: %v", err)
+			}
+			backend.sign(req, objectPath, test.body, now)
+
+			if got := req.Header.Get("x-amz-date"); got != "20250130T151001Z" {
+				t.Errorf("expected x-amz-date 20250130T151001Z, got %s", got)
+			}
+			if got := req.Header.Get("x-amz-content-sha256"); got != test.expectedPayloadHash {
+				t.Errorf("expected x-amz-content-sha256 %s, got %s", test.expectedPayloadHash, got)
+			}
+			if got := req.Header.Get("Authorization"); got != test.expectedAuthorization {
+				t.Errorf("expected Authorization %s, got %s", test.expectedAuthorization, got)
+			}
+		})
+	}
+}
+
+func TestNewS3Backend(t *testing.T) {
+	tests := []struct {
+		name             string
+		endpoint         string
+		bucket           string
+		region           string
+		accessKeyID      string
+		insecure         bool
+		expectedEndpoint string
+		expectedRegion   string
+		expectedScheme   string
+		expectedError    bool
+	}{
+		{
+			name:             "AWS endpoint of the default region",
+			bucket:           "captures",
+			accessKeyID:      testAccessKeyID,
+			expectedEndpoint: "s3.us-east-1.amazonaws.com",
+			expectedRegion:   "us-east-1",
+			expectedScheme:   "https",
+		},
+		{
+			name:             "custom insecure endpoint",
+			endpoint:         "minio.example.com:9000/",
+			bucket:           "captures",
+			region:           "eu-west-1",
+			accessKeyID:      testAccessKeyID,
+			insecure:         true,
+			expectedEndpoint: "minio.example.com:9000",
+			expectedRegion:   "eu-west-1",
+			expectedScheme:   "http",
+		},
+		{
+			name:          "missing bucket",
+			accessKeyID:   testAccessKeyID,
+			expectedError: true,
+		},
+		{
+			name:          "missing credentials",
+			bucket:        "captures",
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			backend, err := NewS3Backend(test.endpoint, test.bucket, test.region, test.accessKeyID,
+				testSecretAccessKey, test.insecure)
+			if test.expectedError {
+				if err == nil {
+					t.Fatal("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if backend.endpoint != test.expectedEndpoint {
+				t.Errorf("expected endpoint %s, got %s", test.expectedEndpoint, backend.endpoint)
+			}
+			if backend.region != test.expectedRegion {
+				t.Errorf("expected region %s, got %s", test.expectedRegion, backend.region)
+			}
+			if backend.scheme != test.expectedScheme {
+				t.Errorf("expected scheme %s, got %s", test.expectedScheme, backend.scheme)
+			}
+		})
+	}
+}
+
+// fakeS3Server is an in-memory S3 bucket, rejecting the requests that are not signed
+type fakeS3Server struct {
+	mutex   sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeS3Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential="+testAccessKeyID+"/") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		s.objects[r.URL.Path] = body
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		object, exists := s.objects[r.URL.Path]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(object)
+	case http.MethodDelete:
+		delete(s.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestS3Backend(t *testing.T) {
+	server := httptest.NewServer(&fakeS3Server{objects: map[string][]byte{}})
+	defer server.Close()
+
+	backend, err := NewS3Backend(strings.TrimPrefix(server.URL, "http://"), "captures", "", testAccessKeyID,
+		testSecretAccessKey, true)
+	if err != nil {
+		t.Fatalf("unexpected error creating the backend: %v", err)
+	}
+	ctx := context.Background()
+
+	ref, err := backend.Put(ctx, "recoveryconfig-sample-service-test.json", []byte(`{"kind":"Service"}`))
+	if err != nil {
+		t.Fatalf("unexpected error putting the payload: %v", err)
+	}
+	if ref != "s3://captures/recoveryconfig-sample-service-test.json" {
+		t.Errorf("unexpected reference %s", ref)
+	}
+
+	payload, err := backend.Get(ctx, ref)
+	if err != nil {
+		t.Fatalf("unexpected error getting the payload: %v", err)
+	}
+	if string(payload) != `{"kind":"Service"}` {
+		t.Errorf("unexpected payload %s", payload)
+	}
+
+	if err = backend.Delete(ctx, ref); err != nil {
+		t.Fatalf("unexpected error deleting the payload: %v", err)
+	}
+	if _, err = backend.Get(ctx, ref); err == nil {
+		t.Error("expected an error getting a deleted payload, got none")
+	}
+	if err = backend.Delete(ctx, ref); err != nil {
+		t.Errorf("unexpected error deleting a missing payload: %v", err)
+	}
+
+	for _, invalidRef := range []string{
+		"s3://other-bucket/recoveryconfig-sample-service-test.json",
+		"filesystem://recoveryconfig-sample-service-test.json",
+		"s3://captures/../secret.json",
+	} {
+		if _, err = backend.Get(ctx, invalidRef); err == nil {
+			t.Errorf("expected an error getting the invalid reference %s, got none", invalidRef)
+		}
+	}
+}
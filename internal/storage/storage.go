@@ -0,0 +1,88 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+const (
+
+	// Backend types
+	BackendTypeEtcd       = "etcd"
+	BackendTypeFilesystem = "filesystem"
+	BackendTypeS3         = "s3"
+
+	// Error messages
+	unknownBackendTypeError = "unknown storage backend type '%s'"
+	invalidKeyError         = "invalid payload key '%s'"
+	invalidReferenceError   = "payload reference '%s' does not belong to the %s backend"
+)
+
+// Backend stores the payload of the captured resources outside the cluster
+type Backend interface {
+	// Put stores the payload under the given key and returns the reference used to retrieve it later
+	Put(ctx context.Context, key string, payload []byte) (ref string, err error)
+
+	// Get returns the payload stored under the given reference
+	Get(ctx context.Context, ref string) (payload []byte, err error)
+
+	// Delete removes the payload stored under the given reference
+	Delete(ctx context.Context, ref string) error
+}
+
+// Options defines the settings used to build a storage Backend
+type Options struct {
+	Type string
+
+	// Filesystem backend
+	FilesystemPath string
+
+	// S3 backend
+	S3Endpoint        string
+	S3Bucket          string
+	S3Region          string
+	S3AccessKeyID     string
+	S3SecretAccessKey string
+	S3Insecure        bool
+}
+
+// NewBackend returns the Backend defined in the options.
+// The etcd backend keeps the payload inline in the RecoveryResource, so no Backend is returned for it
+func NewBackend(opts Options) (Backend, error) {
+	switch opts.Type {
+	case "", BackendTypeEtcd:
+		return nil, nil
+	case BackendTypeFilesystem:
+		return NewFilesystemBackend(opts.FilesystemPath)
+	case BackendTypeS3:
+		return NewS3Backend(opts.S3Endpoint, opts.S3Bucket, opts.S3Region,
+			opts.S3AccessKeyID, opts.S3SecretAccessKey, opts.S3Insecure)
+	}
+
+	return nil, fmt.Errorf(unknownBackendTypeError, opts.Type)
+}
+
+// validateKey checks that the key can be safely used as a file or object name
+func validateKey(key string) error {
+	if key == "" || strings.Contains(key, "/") || strings.Contains(key, "..") {
+		return fmt.Errorf(invalidKeyError, key)
+	}
+	return nil
+}